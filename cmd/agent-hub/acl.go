@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// runACL shows and changes topic access control lists. Unlike the
+// topic_set_acl tool, it is not restricted to topic owners.
+func (a *App) runACL(args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	rawArgs := args
+	action := "denials"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+
+	// The topic ID may come before or after the flags
+	var positional []string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("acl", flag.ContinueOnError)
+	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
+	owners := fs.String("owners", "", "Comma-separated owners (set)")
+	writers := fs.String("writers", "", "Comma-separated writers (set)")
+	readers := fs.String("readers", "", "Comma-separated readers (set)")
+	limit := fs.Int("limit", 50, "Maximum number of denials to show (denials)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	positional = append(positional, fs.Args()...)
+
+	var topicID int64
+	if len(positional) > 1 {
+		return fmt.Errorf("usage: agent-hub acl %s <topic_id>", action)
+	}
+	if len(positional) == 1 {
+		id, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid topic ID %q", positional[0])
+		}
+		topicID = id
+	}
+	if topicID == 0 && action != "denials" {
+		return fmt.Errorf("usage: agent-hub acl %s <topic_id>", action)
+	}
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+	if action == "set" || action == "clear" {
+		defer func() { auditCLI(database, "acl "+action, rawArgs, err, stderr) }()
+	}
+
+	switch action {
+	case "get":
+		return aclShow(database, topicID, stdout)
+	case "set":
+		acl := db.TopicACL{
+			TopicID: topicID,
+			Owners:  splitList(*owners),
+			Writers: splitList(*writers),
+			Readers: splitList(*readers),
+		}
+		if acl.IsOpen() {
+			return fmt.Errorf("-owners is required (use 'agent-hub acl clear' to open the topic)")
+		}
+		if err := database.SetTopicACL(acl); err != nil {
+			return err
+		}
+		fmt.Fprintln(stderr, "Warning: agents pick their own name and role with bbs_register_agent, so ACLs are only advisory unless 'agent-hub serve -auth' binds them to API tokens")
+		return aclShow(database, topicID, stdout)
+	case "clear":
+		if err := database.SetTopicACL(db.TopicACL{TopicID: topicID}); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Topic %d is open to every agent\n", topicID)
+		return nil
+	case "denials":
+		return aclDenials(database, topicID, *limit, stdout)
+	default:
+		return fmt.Errorf("unknown acl action: %s (expected 'get', 'set', 'clear' or 'denials')", action)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// aclShow prints a topic's ACL.
+func aclShow(database *db.DB, topicID int64, stdout io.Writer) error {
+	acl, err := database.GetTopicACL(topicID)
+	if err != nil {
+		return err
+	}
+	if acl.IsOpen() {
+		fmt.Fprintf(stdout, "Topic %d is open to every agent\n", topicID)
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "Topic %d\n", topicID)
+	fmt.Fprintf(stdout, "  Owners:  %s\n", strings.Join(acl.Owners, ", "))
+	fmt.Fprintf(stdout, "  Writers: %s\n", strings.Join(acl.Writers, ", "))
+	fmt.Fprintf(stdout, "  Readers: %s\n", strings.Join(acl.Readers, ", "))
+	return nil
+}
+
+// aclDenials prints operations refused by topic ACLs, newest first.
+func aclDenials(database *db.DB, topicID int64, limit int, stdout io.Writer) error {
+	denials, err := database.ListACLDenials(topicID, limit)
+	if err != nil {
+		return err
+	}
+	if len(denials) == 0 {
+		fmt.Fprintln(stdout, "No denied operations")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tTOPIC\tAGENT\tROLE\tOPERATION")
+	for _, d := range denials {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", d.CreatedAt, d.TopicID, d.Agent, d.Role, d.Operation)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// runAudit shows and prunes the audit log of hub mutations.
+func (a *App) runAudit(args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	rawArgs := args
+	action := "list"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
+	actor := fs.String("actor", "", "Only show events of this actor (list)")
+	session := fs.String("session", "", "Only show events of this MCP session (list)")
+	source := fs.String("source", "", "Only show events from mcp, cli or tui (list)")
+	toolAction := fs.String("action", "", "Only show this tool or command, e.g. bbs_post or \"token create\" (list)")
+	result := fs.String("result", "", "Only show ok or error results (list)")
+	since := fs.Duration("since", 0, "Only show events from the last duration, e.g. 24h (list)")
+	limit := fs.Int("limit", 50, "Maximum number of events to show (list)")
+	asJSON := fs.Bool("json", false, "Print events as JSON (list)")
+	keepDays := fs.Int("keep-days", db.DefaultAuditRetentionDays, "Delete events older than this many days (prune)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	switch action {
+	case "list":
+		q := db.AuditQuery{
+			Actor:   *actor,
+			Session: *session,
+			Source:  *source,
+			Action:  *toolAction,
+			Result:  *result,
+			Limit:   *limit,
+		}
+		if *since > 0 {
+			q.Since = time.Now().Add(-*since)
+		}
+		return auditList(database, q, *asJSON, stdout)
+	case "prune":
+		defer func() { auditCLI(database, "audit prune", rawArgs, err, stderr) }()
+		n, err := database.PruneAuditEvents(*keepDays)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Deleted %d audit events older than %d days\n", n, *keepDays)
+		return nil
+	default:
+		return fmt.Errorf("unknown audit action: %s (expected 'list' or 'prune')", action)
+	}
+}
+
+// auditList prints audit events, newest first.
+func auditList(database *db.DB, q db.AuditQuery, asJSON bool, stdout io.Writer) error {
+	events, err := database.ListAuditEvents(q)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		if events == nil {
+			events = []db.AuditEvent{}
+		}
+		data, err := json.MarshalIndent(events, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit events: %w", err)
+		}
+		fmt.Fprintln(stdout, string(data))
+		return nil
+	}
+
+	if len(events) == 0 {
+		fmt.Fprintln(stdout, "No audit events")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tACTOR\tSOURCE\tACTION\tRESULT\tARGS\tSESSION")
+	for _, e := range events {
+		res := e.Result
+		if e.Detail != "" {
+			res += ": " + e.Detail
+		}
+		session := e.Session
+		if session == "" {
+			session = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.CreatedAt, e.Actor, e.Source, e.Action, res, e.ArgsHash[:12], session)
+	}
+	return tw.Flush()
+}
+
+// auditCLI records a mutating command in the audit log; err is the
+// command's failure, if any.
+func auditCLI(database *db.DB, action string, args []string, err error, stderr io.Writer) {
+	if recordErr := database.RecordAudit(db.NewAuditEvent(cliActor(), "", db.AuditSourceCLI, action, args, err)); recordErr != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", recordErr)
+	}
+}
+
+// cliActor names the operator running a command.
+func cliActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "cli"
+}
@@ -24,32 +24,48 @@ func (a *App) runDoctor(args []string, stdout io.Writer, stderr io.Writer) error
 
 	// Check Database
 	fmt.Fprintf(stdout, "[*] Checking Database (%s)...\n", *dbPath)
-	database, err := db.Open(*dbPath)
+	database, err := db.OpenUnmigrated(*dbPath)
 	if err != nil {
 		fmt.Fprintf(stdout, "  [ERROR] Failed to open database: %v\n", err)
 		allOk = false
 	} else {
 		defer database.Close()
+
+		// Missing tables are expected while migrations are pending, so drift
+		// is reported as a warning rather than an integrity failure.
+		version, versionErr := database.SchemaVersion()
+		latest := db.LatestSchemaVersion()
+		pending := versionErr == nil && version < latest
+
 		results, err := database.CheckIntegrity()
-		if err != nil {
+		if err != nil && !pending {
 			fmt.Fprintf(stdout, "  [ERROR] Integrity check failed: %v\n", err)
-			fmt.Fprintln(stdout, "  Table status:")
-			for table, exists := range results {
-				status := "OK"
-				if !exists {
-					status = "MISSING"
-				}
-				fmt.Fprintf(stdout, "    - %s: %s\n", table, status)
-			}
 			allOk = false
-		} else {
+		} else if err == nil {
 			fmt.Fprintln(stdout, "  [OK] Database integrity check passed")
-			fmt.Fprintln(stdout, "  Table status:")
-			// Pre-defined table order for consistent output
-			tables := []string{"topics", "messages", "topic_summaries", "agent_presence"}
-			for _, table := range tables {
-				fmt.Fprintf(stdout, "    - %s: OK\n", table)
+		}
+		fmt.Fprintln(stdout, "  Table status:")
+		for _, table := range db.RequiredTables {
+			status := "OK"
+			if !results[table] {
+				status = "MISSING"
 			}
+			fmt.Fprintf(stdout, "    - %s: %s\n", table, status)
+		}
+
+		// Check Schema Version
+		fmt.Fprint(stdout, "[*] Checking Schema Version... ")
+		switch {
+		case versionErr != nil:
+			fmt.Fprintf(stdout, "[ERROR] %v\n", versionErr)
+			allOk = false
+		case version > latest:
+			fmt.Fprintf(stdout, "[ERROR] Database is at version %d but this binary only supports up to %d. Upgrade agent-hub.\n", version, latest)
+			allOk = false
+		case pending:
+			fmt.Fprintf(stdout, "[WARN] Database is at version %d, latest is %d. Run 'agent-hub migrate up'.\n", version, latest)
+		default:
+			fmt.Fprintf(stdout, "[OK] (version %d)\n", version)
 		}
 	}
 
@@ -17,6 +17,12 @@ func (a *App) runHelp(stdout io.Writer) {
 	fmt.Fprintln(stdout, "  orchestrator  Start the autonomous monitor/summarizer")
 	fmt.Fprintln(stdout, "  doctor        Run system diagnostics")
 	fmt.Fprintln(stdout, "  setup         Initialize database and configuration")
+	fmt.Fprintln(stdout, "  migrate       Show schema status or apply migrations (status|up)")
+	fmt.Fprintln(stdout, "  token         Manage API tokens for the HTTP transports (create|list|revoke)")
+	fmt.Fprintln(stdout, "  acl           Show or change topic access control lists (get|set|clear|denials)")
+	fmt.Fprintln(stdout, "  key           Manage message signing keys (generate|list|revoke)")
+	fmt.Fprintln(stdout, "  redact        Manage secret redaction rules and review redactions (list|add|remove|events)")
+	fmt.Fprintln(stdout, "  audit         Show or prune the audit log of hub mutations (list|prune)")
 	fmt.Fprintln(stdout, "  help          Show this help message")
 	fmt.Fprintln(stdout, "\nGlobal Flags (available for most commands):")
 	fmt.Fprintln(stdout, "  -db string    Path to SQLite database (default: "+config.DefaultDBPath()+")")
@@ -24,6 +30,57 @@ func (a *App) runHelp(stdout io.Writer) {
 	fmt.Fprintln(stdout, "  -sse string   Enable SSE mode on address (e.g., :8080)")
 	fmt.Fprintln(stdout, "  -sender name  Default sender name for messages")
 	fmt.Fprintln(stdout, "  -role role    Agent role")
+	fmt.Fprintln(stdout, "  -auth         Require an API token on every HTTP request (SSE mode)")
+	fmt.Fprintln(stdout, "  -cors-origins list  Comma-separated origins allowed to make cross-origin requests (\"*\" = any)")
+	fmt.Fprintln(stdout, "  -config path  Agent config file holding the signing key (default: "+config.DefaultConfigPath()+")")
+	fmt.Fprintln(stdout, "  -require-signatures  Refuse unsigned posts from agents with a registered signing key")
+	fmt.Fprintln(stdout, "  -redact action  What built-in secret detectors do: mask, reject or off (default: mask)")
+	fmt.Fprintln(stdout, "  -sampling     Let the orchestrator summarize via clients that support MCP sampling")
+	fmt.Fprintln(stdout, "  -sampling-clients names  Client names that may be asked, in order of preference (default: any)")
+	fmt.Fprintln(stdout, "  -sampling-policy policy  Client selection: first or round-robin (default: first)")
+	fmt.Fprintln(stdout, "\nOrchestrator Flags:")
+	fmt.Fprintln(stdout, "  -sampling=false        Do not try MCP sampling before the LLM provider")
+	fmt.Fprintln(stdout, "  -provider name         LLM provider: gemini, openai, anthropic or mock (default: gemini)")
+	fmt.Fprintln(stdout, "  -model name            Model to use for summaries (default depends on the provider)")
+	fmt.Fprintln(stdout, "  -base-url url          LLM API endpoint (e.g., http://localhost:11434/v1 for Ollama)")
+	fmt.Fprintln(stdout, "  -summary-chunk-size n  Maximum messages per summarizer call when catching up (default: 50)")
+	fmt.Fprintln(stdout, "  -inactivity-timeout d  Nudge agents when a question goes unanswered this long (default: 5m)")
+	fmt.Fprintln(stdout, "  -nudge-cooldown d      Minimum time between nudges in one topic (default: 30m)")
+	fmt.Fprintln(stdout, "  -quiet-hours range     Local time window without nudges (e.g., 22:00-07:00)")
+	fmt.Fprintln(stdout, "  -audit-retention-days n  Delete audit events older than n days (default: 90, 0 = keep forever)")
+	fmt.Fprintln(stdout, "\nMigrate Flags:")
+	fmt.Fprintln(stdout, "  -dry-run      Show pending migrations without applying them")
+	fmt.Fprintln(stdout, "  -no-backup    Skip the backup taken before migrating")
+	fmt.Fprintln(stdout, "\nToken Flags:")
+	fmt.Fprintln(stdout, "  -agent name   Agent the token acts as (create)")
+	fmt.Fprintln(stdout, "  -role role    Role of the token (create, default: agent)")
+	fmt.Fprintln(stdout, "  -note text    Note shown in the token list (create)")
+	fmt.Fprintln(stdout, "  -all          Include revoked tokens (list)")
+	fmt.Fprintln(stdout, "\nACL Flags:")
+	fmt.Fprintln(stdout, "  -owners list  Comma-separated agents or role:<name> that manage the topic (set)")
+	fmt.Fprintln(stdout, "  -writers list Comma-separated agents or role:<name> that may read and post (set)")
+	fmt.Fprintln(stdout, "  -readers list Comma-separated agents or role:<name> that may only read (set)")
+	fmt.Fprintln(stdout, "  -limit n      Maximum number of denied operations to show (denials, default: 50)")
+	fmt.Fprintln(stdout, "\nKey Flags:")
+	fmt.Fprintln(stdout, "  -agent name   Agent the key signs for (generate, default: agent_id of the config)")
+	fmt.Fprintln(stdout, "  -config path  Config file the private key is stored in (generate)")
+	fmt.Fprintln(stdout, "  -force        Replace a signing key already in the config (generate)")
+	fmt.Fprintln(stdout, "  -all          Include revoked keys (list)")
+	fmt.Fprintln(stdout, "\nRedact Flags:")
+	fmt.Fprintln(stdout, "  -pattern re   Regular expression of the rule; a (?P<secret>...) group masks only that part (add)")
+	fmt.Fprintln(stdout, "  -action name  mask or reject (add, default: mask)")
+	fmt.Fprintln(stdout, "  -sender name  Only show events of this sender (events)")
+	fmt.Fprintln(stdout, "  -limit n      Maximum number of events to show (events, default: 50)")
+	fmt.Fprintln(stdout, "\nAudit Flags:")
+	fmt.Fprintln(stdout, "  -actor name   Only show events of this agent, operator or OS user (list)")
+	fmt.Fprintln(stdout, "  -session id   Only show events of this MCP session (list)")
+	fmt.Fprintln(stdout, "  -source name  Only show events from mcp, cli or tui (list)")
+	fmt.Fprintln(stdout, "  -action name  Only show this tool or command, e.g. bbs_post or \"token create\" (list)")
+	fmt.Fprintln(stdout, "  -result name  Only show ok or error results (list)")
+	fmt.Fprintln(stdout, "  -since d      Only show events from the last duration, e.g. 24h (list)")
+	fmt.Fprintln(stdout, "  -limit n      Maximum number of events to show (list, default: 50)")
+	fmt.Fprintln(stdout, "  -json         Print events as JSON (list)")
+	fmt.Fprintln(stdout, "  -keep-days n  Delete events older than n days (prune, default: 90)")
 	fmt.Fprintln(stdout, "\nSSE Connection Example:")
 	fmt.Fprintln(stdout, "  When running with '-sse :8080', connect your MCP client to:")
 	fmt.Fprintln(stdout, "  http://localhost:8080/sse")
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// runKey manages the ed25519 keys agents sign their messages with.
+func (a *App) runKey(args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	rawArgs := args
+	action := "list"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+
+	// The key ID of revoke may come before or after the flags
+	var positional []string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("key", flag.ContinueOnError)
+	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
+	configPath := fs.String("config", config.DefaultConfigPath(), "Agent config file the private key is stored in (generate)")
+	agent := fs.String("agent", "", "Agent the key signs for (generate, default: agent_id of the config)")
+	force := fs.Bool("force", false, "Replace a signing key already in the config (generate)")
+	all := fs.Bool("all", false, "Include revoked keys (list)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	positional = append(positional, fs.Args()...)
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+	if action == "generate" || action == "revoke" {
+		defer func() { auditCLI(database, "key "+action, rawArgs, err, stderr) }()
+	}
+
+	switch action {
+	case "generate":
+		return keyGenerate(database, *configPath, *agent, *force, stdout)
+	case "list":
+		return keyList(database, *all, stdout)
+	case "revoke":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: agent-hub key revoke <id>")
+		}
+		id, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key ID %q", positional[0])
+		}
+		if err := database.RevokeSigningKey(id); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Revoked signing key %d\n", id)
+		return nil
+	default:
+		return fmt.Errorf("unknown key action: %s (expected 'generate', 'list' or 'revoke')", action)
+	}
+}
+
+// keyGenerate creates a signing key, stores the private key in the agent's
+// config and registers the public key in the hub. If the config can't be
+// written the new key is revoked again, and a key it replaces is revoked.
+func keyGenerate(database *db.DB, configPath, agent string, force bool, stdout io.Writer) error {
+	cfg := &config.Config{}
+	if err := cfg.LoadFromFile(configPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	if agent == "" {
+		agent = cfg.AgentID
+	}
+	if agent == "" {
+		return fmt.Errorf("-agent is required (the config has no agent_id)")
+	}
+	if cfg.AgentID != "" && cfg.AgentID != agent {
+		return fmt.Errorf("%s belongs to agent %s, not %s; use -config to pick another file", configPath, cfg.AgentID, agent)
+	}
+	if cfg.SigningKey != "" && !force {
+		return fmt.Errorf("%s already has a signing key (use -force to replace it)", configPath)
+	}
+
+	encoded, err := db.GenerateSigningKey()
+	if err != nil {
+		return err
+	}
+	key, err := db.ParseSigningKey(encoded)
+	if err != nil {
+		return err
+	}
+
+	replaced := cfg.SigningKey
+
+	registered, err := database.RegisterSigningKey(agent, db.PublicKeyString(key))
+	if err != nil {
+		return err
+	}
+
+	// Without the private key in the config nobody can sign with the new
+	// key, so it must not stay active
+	cfg.AgentID = agent
+	cfg.SigningKey = encoded
+	if err := cfg.SaveToFile(configPath); err != nil {
+		if revokeErr := database.RevokeSigningKey(registered.ID); revokeErr != nil {
+			return fmt.Errorf("%w (and failed to revoke the new key %d: %v)", err, registered.ID, revokeErr)
+		}
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Created signing key %d for agent=%s\n", registered.ID, agent)
+	if replaced != "" {
+		if err := revokeReplacedKey(database, agent, replaced, stdout); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "  Public key:  %s\n", registered.PublicKey)
+	fmt.Fprintf(stdout, "  Private key: stored in %s\n", configPath)
+	fmt.Fprintln(stdout, "Run 'agent-hub serve' with this config to sign the agent's posts.")
+	return nil
+}
+
+// revokeReplacedKey revokes the registered key of a private key that a new
+// one replaced in the agent's config, so it no longer verifies messages.
+func revokeReplacedKey(database *db.DB, agent, encoded string, stdout io.Writer) error {
+	key, err := db.ParseSigningKey(encoded)
+	if err != nil {
+		// Nothing could have been signed with a key that doesn't parse
+		return nil
+	}
+	publicKey := db.PublicKeyString(key)
+
+	keys, err := database.ListSigningKeys(false)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.Agent != agent || k.PublicKey != publicKey {
+			continue
+		}
+		if err := database.RevokeSigningKey(k.ID); err != nil {
+			return fmt.Errorf("failed to revoke replaced key %d: %w", k.ID, err)
+		}
+		fmt.Fprintf(stdout, "Revoked replaced signing key %d\n", k.ID)
+	}
+	return nil
+}
+
+// keyList prints the registered public keys.
+func keyList(database *db.DB, all bool, stdout io.Writer) error {
+	keys, err := database.ListSigningKeys(all)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Fprintln(stdout, "No signing keys (create one with 'agent-hub key generate -agent <name>')")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tAGENT\tPUBLIC KEY\tCREATED\tSTATUS")
+	for _, k := range keys {
+		status := "active"
+		if k.RevokedAt != "" {
+			status = "revoked " + k.RevokedAt
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", k.ID, k.Agent, k.PublicKey, k.CreatedAt, status)
+	}
+	return tw.Flush()
+}
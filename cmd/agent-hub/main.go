@@ -47,6 +47,18 @@ func (a *App) Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Wr
 		return a.runDoctor(args[2:], stdout, stderr)
 	case "setup":
 		return a.runSetup(args[2:], stdout, stderr)
+	case "migrate":
+		return a.runMigrate(args[2:], stdout, stderr)
+	case "token":
+		return a.runToken(args[2:], stdout, stderr)
+	case "acl":
+		return a.runACL(args[2:], stdout, stderr)
+	case "key":
+		return a.runKey(args[2:], stdout, stderr)
+	case "redact":
+		return a.runRedact(args[2:], stdout, stderr)
+	case "audit":
+		return a.runAudit(args[2:], stdout, stderr)
 	case "help", "--help", "-h":
 		a.runHelp(stdout)
 		return nil
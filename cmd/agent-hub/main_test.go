@@ -2,8 +2,12 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
 )
 
 func TestNewApp(t *testing.T) {
@@ -69,6 +73,17 @@ func TestApp_Run_Orchestrator_InvalidFlag(t *testing.T) {
 	}
 }
 
+func TestApp_Run_Orchestrator_InvalidQuietHours(t *testing.T) {
+	app := NewApp()
+	var stdout, stderr bytes.Buffer
+
+	err := app.Run([]string{"agent-hub", "orchestrator", "-db", t.TempDir() + "/test.db", "-quiet-hours", "late"}, nil, &stdout, &stderr)
+
+	if err == nil || !strings.Contains(err.Error(), "quiet hours") {
+		t.Errorf("expected quiet hours error, got %v", err)
+	}
+}
+
 func TestApp_Run_Serve_InvalidDBPath(t *testing.T) {
 	app := NewApp()
 	var stdout, stderr bytes.Buffer
@@ -137,3 +152,294 @@ func TestApp_Run_Doctor_WithEnvVars(t *testing.T) {
 	err := app.Run([]string{"agent-hub", "doctor", "-db", ":memory:"}, nil, &stdout, &stderr)
 	_ = err
 }
+
+func TestApp_Run_Migrate(t *testing.T) {
+	app := NewApp()
+	dbPath := t.TempDir() + "/test.db"
+
+	var stdout, stderr bytes.Buffer
+	if err := app.Run([]string{"agent-hub", "migrate", "status", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("migrate status failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Schema version: 0") || !strings.Contains(stdout.String(), "pending") {
+		t.Errorf("expected pending migrations in status output, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "migrate", "up", "-dry-run", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("migrate up -dry-run failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Would apply 0001_initial") {
+		t.Errorf("expected dry run to list migrations, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "migrate", "up", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("migrate up failed: %v", err)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "migrate", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if strings.Contains(stdout.String(), "pending") {
+		t.Errorf("expected no pending migrations after 'up', got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"agent-hub", "migrate", "down", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error for unknown migrate action")
+	}
+}
+
+func TestApp_Run_Token(t *testing.T) {
+	app := NewApp()
+	dbPath := t.TempDir() + "/tokens.db"
+	var stdout, stderr bytes.Buffer
+
+	if err := app.Run([]string{"agent-hub", "token", "create", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error without -agent")
+	}
+	if err := app.Run([]string{"agent-hub", "token", "create", "-db", dbPath, "-agent", "alice", "-role", "reviewer", "-note", "laptop"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("token create failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Created token 1 for agent=alice, role=reviewer") || !strings.Contains(stdout.String(), "ahub_") {
+		t.Errorf("expected the new token in output, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "token", "list", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("token list failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "alice") || !strings.Contains(stdout.String(), "laptop") || !strings.Contains(stdout.String(), "active") {
+		t.Errorf("expected alice's token in list, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "token", "revoke", "1", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("token revoke failed: %v", err)
+	}
+	if err := app.Run([]string{"agent-hub", "token", "revoke", "1", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error revoking a revoked token")
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "token", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("token failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No tokens") {
+		t.Errorf("expected no active tokens, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"agent-hub", "token", "rotate", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error for unknown token action")
+	}
+}
+
+func TestApp_Run_ACL(t *testing.T) {
+	app := NewApp()
+	dbPath := t.TempDir() + "/acl.db"
+	var stdout, stderr bytes.Buffer
+
+	database, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	topicID, _ := database.CreateTopic("Internal")
+	database.RecordACLDenial(topicID, db.Principal{Agent: "ext-1", Role: "contractor"}, "read")
+	database.Close()
+
+	if err := app.Run([]string{"agent-hub", "acl", "set", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error without topic ID")
+	}
+	if err := app.Run([]string{"agent-hub", "acl", "set", "1", "-db", dbPath, "-readers", "bob"}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error without -owners")
+	}
+	if err := app.Run([]string{"agent-hub", "acl", "set", "1", "-db", dbPath, "-owners", "alice", "-writers", "bob, role:reviewer"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("acl set failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Owners:  alice") || !strings.Contains(stdout.String(), "Writers: bob, role:reviewer") {
+		t.Errorf("expected the new ACL in output, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "only advisory unless 'agent-hub serve -auth'") {
+		t.Errorf("expected a warning that the ACL is advisory without -auth, got:\n%s", stderr.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "acl", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("acl denials failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "ext-1") || !strings.Contains(stdout.String(), "read") {
+		t.Errorf("expected the recorded denial, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "acl", "clear", "1", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("acl clear failed: %v", err)
+	}
+	if err := app.Run([]string{"agent-hub", "acl", "get", "1", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("acl get failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "open to every agent") {
+		t.Errorf("expected an open topic, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"agent-hub", "acl", "grant", "1", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error for unknown acl action")
+	}
+}
+
+func TestApp_Run_Key(t *testing.T) {
+	app := NewApp()
+	dir := t.TempDir()
+	dbPath := dir + "/keys.db"
+	configPath := dir + "/alice.json"
+	var stdout, stderr bytes.Buffer
+
+	if err := app.Run([]string{"agent-hub", "key", "generate", "-db", dbPath, "-config", configPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error without -agent")
+	}
+	if err := app.Run([]string{"agent-hub", "key", "generate", "-db", dbPath, "-config", configPath, "-agent", "alice"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("key generate failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Created signing key 1 for agent=alice") {
+		t.Errorf("expected the new key in output, got:\n%s", stdout.String())
+	}
+
+	cfg := &config.Config{}
+	if err := cfg.LoadFromFile(configPath); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.AgentID != "alice" || cfg.SigningKey == "" {
+		t.Errorf("expected alice's private key in the config, got %+v", cfg)
+	}
+
+	if err := app.Run([]string{"agent-hub", "key", "generate", "-db", dbPath, "-config", configPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error replacing a key without -force")
+	}
+	if err := app.Run([]string{"agent-hub", "key", "generate", "-db", dbPath, "-config", configPath, "-agent", "bob", "-force"}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error generating for another agent's config")
+	}
+
+	// Replacing the key revokes the old one
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "key", "generate", "-db", dbPath, "-config", configPath, "-force"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("key generate -force failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Created signing key 2") || !strings.Contains(stdout.String(), "Revoked replaced signing key 1") {
+		t.Errorf("expected key 1 replaced by key 2, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "key", "list", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("key list failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "alice") || !strings.Contains(stdout.String(), "active") {
+		t.Errorf("expected alice's key in list, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"agent-hub", "key", "revoke", "2", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("key revoke failed: %v", err)
+	}
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "key", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("key failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No signing keys") {
+		t.Errorf("expected no active keys, got:\n%s", stdout.String())
+	}
+}
+
+func TestApp_Run_Redact(t *testing.T) {
+	app := NewApp()
+	dbPath := t.TempDir() + "/redact.db"
+	var stdout, stderr bytes.Buffer
+
+	if err := app.Run([]string{"agent-hub", "redact", "add", "ticket", "-db", dbPath}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error without -pattern")
+	}
+	if err := app.Run([]string{"agent-hub", "redact", "add", "ticket", "-db", dbPath, "-pattern", `INTERNAL-\d+`, "-action", "reject"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("redact add failed: %v", err)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "redact", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("redact list failed: %v", err)
+	}
+	for _, want := range []string{"ticket", "reject", "aws-access-key", "high-entropy"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in rule list, got:\n%s", want, stdout.String())
+		}
+	}
+
+	database, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	database.RecordRedactions("alice", db.RedactionSourcePost, 1, 0, []db.Redaction{{Rule: "ticket", Action: db.RedactReject, Matches: 1}})
+	database.Close()
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "redact", "events", "-db", dbPath, "-sender", "alice"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("redact events failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "topic 1") || !strings.Contains(stdout.String(), "ticket") {
+		t.Errorf("expected alice's event, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"agent-hub", "redact", "remove", "ticket", "-db", dbPath}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("redact remove failed: %v", err)
+	}
+	if err := app.Run([]string{"agent-hub", "serve", "-db", dbPath, "-redact", "drop"}, nil, &stdout, &stderr); err == nil || !strings.Contains(err.Error(), "-redact") {
+		t.Errorf("expected error for an invalid -redact, got %v", err)
+	}
+}
+
+func TestApp_Run_Audit(t *testing.T) {
+	app := NewApp()
+	dbPath := t.TempDir() + "/audit.db"
+	var stdout, stderr bytes.Buffer
+
+	// Mutating commands are audited, listings are not
+	app.Run([]string{"agent-hub", "token", "create", "-db", dbPath, "-agent", "alice"}, nil, &stdout, &stderr)
+	app.Run([]string{"agent-hub", "token", "list", "-db", dbPath}, nil, &stdout, &stderr)
+	app.Run([]string{"agent-hub", "token", "revoke", "42", "-db", dbPath}, nil, &stdout, &stderr)
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "audit", "-db", dbPath, "-json"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("audit failed: %v", err)
+	}
+	var events []db.AuditEvent
+	if err := json.Unmarshal(stdout.Bytes(), &events); err != nil {
+		t.Fatalf("failed to decode audit events: %v\n%s", err, stdout.String())
+	}
+	if len(events) != 2 || events[0].Action != "token revoke" || events[0].Result != db.AuditError || events[1].Action != "token create" || events[1].Source != db.AuditSourceCLI {
+		t.Fatalf("unexpected audit events: %+v", events)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "audit", "list", "-db", dbPath, "-result", "ok"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("audit list failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "token create") || strings.Contains(stdout.String(), "token revoke") {
+		t.Errorf("expected only the successful create, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "audit", "-db", dbPath, "-action", "bbs_post", "-json"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("audit failed: %v", err)
+	}
+	if strings.TrimSpace(stdout.String()) != "[]" {
+		t.Errorf("expected an empty JSON list, got:\n%s", stdout.String())
+	}
+
+	if err := app.Run([]string{"agent-hub", "audit", "prune", "-db", dbPath, "-keep-days", "0"}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error for -keep-days 0")
+	}
+	stdout.Reset()
+	if err := app.Run([]string{"agent-hub", "audit", "prune", "-db", dbPath, "-keep-days", "30"}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("audit prune failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Deleted 0 audit events") {
+		t.Errorf("expected nothing to be pruned, got:\n%s", stdout.String())
+	}
+}
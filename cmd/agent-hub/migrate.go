@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// runMigrate inspects or upgrades the database schema.
+func (a *App) runMigrate(args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	rawArgs := args
+	action := "status"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
+	dryRun := fs.Bool("dry-run", false, "Show pending migrations without applying them")
+	noBackup := fs.Bool("no-backup", false, "Skip the database backup taken before migrating")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := db.OpenUnmigrated(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	switch action {
+	case "status":
+		return migrateStatus(database, stdout)
+	case "up":
+		if !*dryRun {
+			// Recorded once the audit log exists, i.e. after migrating
+			defer func() { auditCLI(database, "migrate up", rawArgs, err, stderr) }()
+		}
+		return migrateUp(database, db.MigrateOptions{DryRun: *dryRun, NoBackup: *noBackup}, stdout)
+	default:
+		return fmt.Errorf("unknown migrate action: %s (expected 'status' or 'up')", action)
+	}
+}
+
+// migrateStatus prints applied and pending migrations.
+func migrateStatus(database *db.DB, stdout io.Writer) error {
+	version, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	migrations, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Schema version: %d (latest: %d)\n", version, db.LatestSchemaVersion())
+	for _, m := range migrations {
+		status := "pending"
+		if m.Version <= version {
+			status = "applied"
+		}
+		fmt.Fprintf(stdout, "  %04d_%s: %s\n", m.Version, m.Name, status)
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d)", version, len(migrations))
+	}
+	return nil
+}
+
+// migrateUp applies pending migrations (or lists them for a dry run).
+func migrateUp(database *db.DB, opts db.MigrateOptions, stdout io.Writer) error {
+	result, err := database.Migrate(opts)
+	if result != nil && result.BackupPath != "" {
+		fmt.Fprintf(stdout, "Backup written to %s\n", result.BackupPath)
+	}
+	if err != nil {
+		if result != nil && result.To > result.From {
+			fmt.Fprintf(stdout, "Migrated from version %d to %d before failing\n", result.From, result.To)
+		}
+		return err
+	}
+
+	if len(result.Applied) == 0 {
+		fmt.Fprintf(stdout, "Schema is up to date (version %d)\n", result.From)
+		return nil
+	}
+
+	verb := "Applied"
+	if opts.DryRun {
+		verb = "Would apply"
+	}
+	for _, m := range result.Applied {
+		fmt.Fprintf(stdout, "  %s %04d_%s\n", verb, m.Version, m.Name)
+	}
+	fmt.Fprintf(stdout, "Schema version: %d -> %d\n", result.From, result.To)
+	return nil
+}
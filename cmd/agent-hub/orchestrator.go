@@ -21,6 +21,18 @@ func (a *App) runOrchestrator(args []string, stdout io.Writer, stderr io.Writer)
 	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
 	senderFlag := fs.String("sender", "", "Default sender name for messages (overrides BBS_AGENT_ID env var)")
 	roleFlag := fs.String("role", "", "Agent role (overrides BBS_AGENT_ROLE env var)")
+	hubConfig := hub.DefaultConfig()
+	fs.IntVar(&hubConfig.SummaryChunkSize, "summary-chunk-size", hubConfig.SummaryChunkSize, "Maximum messages per summarizer call when catching up on a topic")
+	fs.DurationVar(&hubConfig.InactivityTimeout, "inactivity-timeout", hubConfig.InactivityTimeout, "Nudge a topic after this long without a reply (0 disables)")
+	fs.DurationVar(&hubConfig.NudgeCooldown, "nudge-cooldown", hubConfig.NudgeCooldown, "Minimum time between nudges in one topic")
+	fs.StringVar(&hubConfig.QuietHours, "quiet-hours", "", "Local time window without nudges, e.g. 22:00-07:00")
+	fs.IntVar(&hubConfig.AuditRetentionDays, "audit-retention-days", hubConfig.AuditRetentionDays, "Delete audit events older than this many days (0 keeps them forever)")
+	fs.DurationVar(&hubConfig.EventRetention, "event-retention", hubConfig.EventRetention, "Delete change feed events (used to wake wait_notify) and sampling requests older than this (0 keeps them forever)")
+	fs.BoolVar(&hubConfig.Sampling, "sampling", hubConfig.Sampling, "Summarize through MCP sampling via 'agent-hub serve -sampling' before the LLM provider")
+	fs.DurationVar(&hubConfig.SamplingTimeout, "sampling-timeout", hubConfig.SamplingTimeout, "How long to wait for a sampled summary")
+	fs.StringVar(&hubConfig.Provider, "provider", hubConfig.Provider, "LLM provider for summaries: gemini, openai, anthropic or mock")
+	fs.StringVar(&hubConfig.Model, "model", "", "Model to use for summaries (default depends on the provider)")
+	fs.StringVar(&hubConfig.BaseURL, "base-url", "", "LLM API endpoint, e.g. http://localhost:11434/v1 for Ollama")
 
 	if err := fs.Parse(args); err != nil {
 		return fmt.Errorf("failed to parse flags: %w", err)
@@ -70,7 +82,7 @@ func (a *App) runOrchestrator(args []string, stdout io.Writer, stderr io.Writer)
 		cancel()
 	}()
 
-	orchestrator := hub.NewOrchestrator(database, hub.DefaultConfig())
+	orchestrator := hub.NewOrchestrator(database, hubConfig)
 	if err := orchestrator.Start(ctx); err != nil && err != context.Canceled {
 		return fmt.Errorf("orchestrator error: %w", err)
 	}
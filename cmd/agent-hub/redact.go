@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// runRedact manages the secret redaction rules and shows redaction events.
+func (a *App) runRedact(args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	rawArgs := args
+	action := "list"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+
+	// The rule name of add and remove may come before or after the flags
+	var positional []string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("redact", flag.ContinueOnError)
+	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
+	pattern := fs.String("pattern", "", "Regular expression the rule matches; a (?P<secret>...) group masks only that part (add)")
+	ruleAction := fs.String("action", db.RedactMask, "What the rule does with matching content: mask or reject (add)")
+	sender := fs.String("sender", "", "Only show events of this sender (events)")
+	limit := fs.Int("limit", 50, "Maximum number of events to show (events)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	positional = append(positional, fs.Args()...)
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+	if action == "add" || action == "remove" {
+		defer func() { auditCLI(database, "redact "+action, rawArgs, err, stderr) }()
+	}
+
+	switch action {
+	case "list":
+		return redactList(database, stdout)
+	case "add":
+		if len(positional) != 1 || *pattern == "" {
+			return fmt.Errorf("usage: agent-hub redact add <name> -pattern <regex> [-action mask|reject]")
+		}
+		rule, err := database.AddRedactionRule(positional[0], *pattern, *ruleAction)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Added redaction rule %s (%s)\n", rule.Name, rule.Action)
+		return nil
+	case "remove":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: agent-hub redact remove <name>")
+		}
+		if err := database.RemoveRedactionRule(positional[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Removed redaction rule %s\n", positional[0])
+		return nil
+	case "events":
+		return redactEvents(database, *sender, *limit, stdout)
+	default:
+		return fmt.Errorf("unknown redact action: %s (expected 'list', 'add', 'remove' or 'events')", action)
+	}
+}
+
+// redactList prints the added rules followed by the built-in detectors.
+func redactList(database *db.DB, stdout io.Writer) error {
+	rules, err := database.ListRedactionRules()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tACTION\tPATTERN")
+	for _, r := range rules {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, r.Action, r.Pattern)
+	}
+	for _, r := range db.BuiltinRedactionRules {
+		fmt.Fprintf(tw, "%s\tbuilt-in\t%s\n", r.Name, r.Pattern)
+	}
+	fmt.Fprintf(tw, "%s\tbuilt-in\tlong random-looking tokens with letters and digits\n", db.HighEntropyRule)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Built-in detectors mask secrets unless 'agent-hub serve -redact' says otherwise.")
+	return nil
+}
+
+// redactEvents prints posts and direct messages that tripped a rule,
+// newest first.
+func redactEvents(database *db.DB, sender string, limit int, stdout io.Writer) error {
+	events, err := database.ListRedactionEvents(sender, limit)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		fmt.Fprintln(stdout, "No redaction events")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSENDER\tWHERE\tMESSAGE\tRULE\tACTION\tMATCHES")
+	for _, e := range events {
+		where := "dm"
+		if e.Source == db.RedactionSourcePost {
+			where = fmt.Sprintf("topic %d", e.TopicID)
+		}
+		message := "-"
+		if e.MessageID != 0 {
+			message = fmt.Sprintf("%d", e.MessageID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n", e.CreatedAt, e.Sender, where, message, e.Rule, e.Action, e.Matches)
+	}
+	return tw.Flush()
+}
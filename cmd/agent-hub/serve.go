@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/yklcs/agent-hub-mcp/internal/config"
 	"github.com/yklcs/agent-hub-mcp/internal/db"
@@ -19,11 +21,29 @@ func (a *App) runServe(args []string, stdout io.Writer, stderr io.Writer) error
 	sseAddr := fs.String("sse", "", "Enable SSE mode on address (e.g., :8080)")
 	senderFlag := fs.String("sender", "", "Default sender name for messages (overrides BBS_AGENT_ID env var)")
 	roleFlag := fs.String("role", "", "Agent role (overrides BBS_AGENT_ROLE env var)")
+	adminsFlag := fs.String("admins", "", "Comma-separated agents that may edit and delete anyone's messages (tokens with the admin role always may)")
+	authFlag := fs.Bool("auth", false, "Require an API token (see 'agent-hub token') on every HTTP request")
+	corsOrigins := fs.String("cors-origins", "", "Comma-separated origins allowed to make cross-origin HTTP requests (\"*\" = any)")
+	configPath := fs.String("config", config.DefaultConfigPath(), "Agent config file holding the signing key (see 'agent-hub key')")
+	requireSignatures := fs.Bool("require-signatures", false, "Refuse unsigned posts from agents with a registered signing key")
+	redactFlag := fs.String("redact", db.RedactMask, "What the built-in secret detectors do with posted secrets: mask, reject or off")
+	samplingFlag := fs.Bool("sampling", false, "Let the orchestrator summarize through connected clients that support MCP sampling")
+	samplingClients := fs.String("sampling-clients", "", "Comma-separated client names that may be asked to sample, in order of preference (default: any)")
+	samplingPolicy := fs.String("sampling-policy", mcp.SamplingPolicyFirst, "Client selection: first or round-robin")
+	samplingTimeout := fs.Duration("sampling-timeout", 2*time.Minute, "Time one client may take to answer a sampling request")
 
 	if err := fs.Parse(args); err != nil {
 		return fmt.Errorf("failed to parse flags: %w", err)
 	}
 
+	switch *redactFlag {
+	case db.RedactMask, db.RedactReject:
+	case "off":
+		*redactFlag = ""
+	default:
+		return fmt.Errorf("invalid -redact %q (expected mask, reject or off)", *redactFlag)
+	}
+
 	// Determine sender: flag > env var > default ("unknown")
 	sender := *senderFlag
 	if sender == "" {
@@ -72,7 +92,64 @@ func (a *App) runServe(args []string, stdout io.Writer, stderr io.Writer) error
 
 	srv := mcp.NewServer(database, sender, role)
 
+	if *samplingFlag {
+		cfg := mcp.SamplingConfig{Policy: *samplingPolicy, Timeout: *samplingTimeout}
+		for _, name := range strings.Split(*samplingClients, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.AllowedClients = append(cfg.AllowedClients, name)
+			}
+		}
+		if err := srv.EnableSampling(cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "MCP sampling enabled for the orchestrator (policy: %s)\n", *samplingPolicy)
+	}
+
+	for _, origin := range strings.Split(*corsOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			srv.AllowedOrigins = append(srv.AllowedOrigins, origin)
+		}
+	}
+	if *authFlag {
+		srv.EnableAuth()
+	}
+	srv.AdminAgents = splitList(*adminsFlag)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.SigningKey != "" {
+		key, err := db.ParseSigningKey(cfg.SigningKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *configPath, err)
+		}
+		signer := cfg.GetSender(sender)
+		srv.EnableSigning(signer, key)
+		fmt.Fprintf(stderr, "Signing posts by %s with the key in %s\n", signer, *configPath)
+		if *sseAddr != "" && !*authFlag {
+			fmt.Fprintf(stderr, "Warning: without -auth, posts over HTTP are not signed; only a token bound to %s lets the server sign for it\n", signer)
+		}
+	}
+	srv.RequireSignatures = *requireSignatures
+	srv.Redaction = *redactFlag
+	if *redactFlag == "" {
+		fmt.Fprintln(stderr, "Warning: built-in secret detectors are off (-redact off); only rules from 'agent-hub redact add' apply")
+	}
+
 	if *sseAddr != "" {
+		if *authFlag {
+			tokens, err := database.ListTokens(false)
+			if err != nil {
+				return err
+			}
+			if len(tokens) == 0 {
+				fmt.Fprintln(stderr, "Warning: -auth is set but there are no tokens; create one with 'agent-hub token create -agent <name>'")
+			}
+			fmt.Fprintln(stderr, "Authentication: API token required (Authorization: Bearer <token>)")
+		} else {
+			fmt.Fprintln(stderr, "Warning: authentication is off; anyone who can reach this address can post as any agent (use -auth)")
+		}
 		host := *sseAddr
 		if host[0] == ':' {
 			host = "localhost" + host
@@ -33,6 +33,7 @@ func (a *App) runSetup(args []string, stdout io.Writer, stderr io.Writer) error
 		if err != nil {
 			return fmt.Errorf("failed to initialize database: %w", err)
 		}
+		auditCLI(database, "setup", args, nil, stderr)
 		database.Close()
 		fmt.Fprintln(stdout, "OK")
 	}
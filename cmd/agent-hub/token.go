@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/yklcs/agent-hub-mcp/internal/config"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// runToken manages API tokens for the HTTP transports.
+func (a *App) runToken(args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	rawArgs := args
+	action := "list"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+
+	// The token ID of revoke may come before or after the flags
+	var positional []string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	dbPath := fs.String("db", config.DefaultDBPath(), "Path to SQLite database")
+	agent := fs.String("agent", "", "Agent name the token acts as (create)")
+	role := fs.String("role", "agent", "Agent role of the token (create)")
+	note := fs.String("note", "", "Free-form note, e.g. where the token is used (create)")
+	all := fs.Bool("all", false, "Include revoked tokens (list)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	positional = append(positional, fs.Args()...)
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+	if action == "create" || action == "revoke" {
+		defer func() { auditCLI(database, "token "+action, rawArgs, err, stderr) }()
+	}
+
+	switch action {
+	case "create":
+		return tokenCreate(database, *agent, *role, *note, stdout)
+	case "list":
+		return tokenList(database, *all, stdout)
+	case "revoke":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: agent-hub token revoke <id>")
+		}
+		id, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid token ID %q", positional[0])
+		}
+		if err := database.RevokeToken(id); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Revoked token %d\n", id)
+		return nil
+	default:
+		return fmt.Errorf("unknown token action: %s (expected 'create', 'list' or 'revoke')", action)
+	}
+}
+
+// tokenCreate creates a token and prints it. It can't be shown again.
+func tokenCreate(database *db.DB, agent, role, note string, stdout io.Writer) error {
+	if agent == "" {
+		return fmt.Errorf("-agent is required")
+	}
+
+	token, created, err := database.CreateToken(agent, role, note)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Created token %d for agent=%s, role=%s\n\n", created.ID, created.Agent, created.Role)
+	fmt.Fprintf(stdout, "  %s\n\n", token)
+	fmt.Fprintln(stdout, "Send it as 'Authorization: Bearer <token>'. It is stored hashed and can't be shown again.")
+	return nil
+}
+
+// tokenList prints the tokens without their secrets.
+func tokenList(database *db.DB, all bool, stdout io.Writer) error {
+	tokens, err := database.ListTokens(all)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Fprintln(stdout, "No tokens (create one with 'agent-hub token create -agent <name>')")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPREFIX\tAGENT\tROLE\tCREATED\tLAST USED\tSTATUS\tNOTE")
+	for _, t := range tokens {
+		lastUsed := t.LastUsedAt
+		if lastUsed == "" {
+			lastUsed = "never"
+		}
+		status := "active"
+		if t.RevokedAt != "" {
+			status = "revoked " + t.RevokedAt
+		}
+		fmt.Fprintf(tw, "%d\t%s…\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Prefix, t.Agent, t.Role, t.CreatedAt, lastUsed, status, t.Note)
+	}
+	return tw.Flush()
+}
@@ -17,6 +17,9 @@ type Config struct {
 	AgentID   string `json:"agent_id"`
 	AgentRole string `json:"agent_role"`
 
+	// Message Signing (base64 ed25519 private key, see 'agent-hub key')
+	SigningKey string `json:"signing_key,omitempty"`
+
 	// API Keys
 	GeminiAPIKey string `json:"gemini_api_key"`
 }
@@ -103,6 +106,9 @@ func (c *Config) LoadFromFile(path string) error {
 	if fileConfig.GeminiAPIKey != "" {
 		c.GeminiAPIKey = fileConfig.GeminiAPIKey
 	}
+	if fileConfig.SigningKey != "" {
+		c.SigningKey = fileConfig.SigningKey
+	}
 
 	return nil
 }
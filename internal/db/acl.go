@@ -0,0 +1,254 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topic access levels, from weakest to strongest. Each one includes the
+// ones before it.
+const (
+	AccessReader = "reader" // Read messages and summaries
+	AccessWriter = "writer" // Also post
+	AccessOwner  = "owner"  // Also change the ACL
+)
+
+// accessRank orders the access levels; unknown levels rank lowest.
+var accessRank = map[string]int{AccessReader: 1, AccessWriter: 2, AccessOwner: 3}
+
+// RolePrefix marks an ACL entry that names a role rather than an agent,
+// e.g. "role:reviewer".
+const RolePrefix = "role:"
+
+// Principal is who topic access is checked for: an agent and its role.
+type Principal struct {
+	Agent string
+	Role  string
+	Admin bool // May change any agent's messages (see CanChangeMessage)
+}
+
+// TopicACL lists who may use a topic. Entries are agent names, or role
+// names prefixed with RolePrefix. A topic whose lists are all empty is open
+// to every agent.
+type TopicACL struct {
+	TopicID int64
+	Owners  []string
+	Writers []string
+	Readers []string
+}
+
+// IsOpen reports whether the ACL has no entries.
+func (acl *TopicACL) IsOpen() bool {
+	return len(acl.Owners) == 0 && len(acl.Writers) == 0 && len(acl.Readers) == 0
+}
+
+// Grants reports whether the ACL gives p at least the given access.
+func (acl *TopicACL) Grants(p Principal, access string) bool {
+	if acl.IsOpen() {
+		return true
+	}
+	levels := []struct {
+		access  string
+		entries []string
+	}{{AccessOwner, acl.Owners}, {AccessWriter, acl.Writers}, {AccessReader, acl.Readers}}
+	for _, level := range levels {
+		if accessRank[level.access] < accessRank[access] {
+			continue
+		}
+		for _, entry := range level.entries {
+			if entryMatches(entry, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entryMatches reports whether an ACL entry names p's agent or role.
+func entryMatches(entry string, p Principal) bool {
+	if role, ok := strings.CutPrefix(entry, RolePrefix); ok {
+		return p.Role != "" && role == p.Role
+	}
+	return entry == p.Agent
+}
+
+// splitEntry returns the kind ("agent" or "role") and name of an ACL entry.
+func splitEntry(entry string) (string, string) {
+	if role, ok := strings.CutPrefix(entry, RolePrefix); ok {
+		return "role", role
+	}
+	return "agent", entry
+}
+
+// SetTopicACL replaces a topic's ACL. An agent or role listed under several
+// access levels gets the strongest one. Setting empty lists opens the topic
+// to every agent.
+func (db *DB) SetTopicACL(acl TopicACL) error {
+	entries := make(map[string]string)
+	levels := []struct {
+		access  string
+		entries []string
+	}{{AccessReader, acl.Readers}, {AccessWriter, acl.Writers}, {AccessOwner, acl.Owners}}
+	for _, level := range levels {
+		for _, entry := range level.entries {
+			entry = strings.TrimSpace(entry)
+			if _, name := splitEntry(entry); name == "" {
+				return fmt.Errorf("ACL entries must name an agent or %s<name>, got %q", RolePrefix, entry)
+			}
+			entries[entry] = level.access
+		}
+	}
+	if len(entries) > 0 && len(acl.Owners) == 0 {
+		return fmt.Errorf("a topic with an ACL needs at least one owner")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM topics WHERE id = ?)", acl.TopicID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up topic %d: %w", acl.TopicID, err)
+	}
+	if !exists {
+		return fmt.Errorf("topic %d not found", acl.TopicID)
+	}
+
+	if _, err := tx.Exec("DELETE FROM topic_acl WHERE topic_id = ?", acl.TopicID); err != nil {
+		return fmt.Errorf("failed to clear topic ACL: %w", err)
+	}
+	for entry, access := range entries {
+		kind, name := splitEntry(entry)
+		if _, err := tx.Exec(
+			"INSERT INTO topic_acl (topic_id, kind, principal, access) VALUES (?, ?, ?, ?)",
+			acl.TopicID, kind, name, access,
+		); err != nil {
+			return fmt.Errorf("failed to set topic ACL: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit topic ACL: %w", err)
+	}
+	return nil
+}
+
+// GetTopicACL retrieves a topic's ACL, with entries in name order. Open
+// topics have empty lists.
+func (db *DB) GetTopicACL(topicID int64) (*TopicACL, error) {
+	rows, err := db.Query(
+		"SELECT kind, principal, access FROM topic_acl WHERE topic_id = ? ORDER BY kind, principal",
+		topicID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query topic ACL: %w", err)
+	}
+	defer rows.Close()
+
+	acl := &TopicACL{TopicID: topicID}
+	for rows.Next() {
+		var kind, name, access string
+		if err := rows.Scan(&kind, &name, &access); err != nil {
+			return nil, fmt.Errorf("failed to scan topic ACL: %w", err)
+		}
+		if kind == "role" {
+			name = RolePrefix + name
+		}
+		switch access {
+		case AccessOwner:
+			acl.Owners = append(acl.Owners, name)
+		case AccessWriter:
+			acl.Writers = append(acl.Writers, name)
+		default:
+			acl.Readers = append(acl.Readers, name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating topic ACL: %w", err)
+	}
+
+	return acl, nil
+}
+
+// CheckTopicAccess reports whether p has at least the given access to a
+// topic.
+func (db *DB) CheckTopicAccess(topicID int64, p Principal, access string) (bool, error) {
+	acl, err := db.GetTopicACL(topicID)
+	if err != nil {
+		return false, err
+	}
+	return acl.Grants(p, access), nil
+}
+
+// readableTopicClause returns an SQL condition, and its arguments, that
+// holds when p may read the topic whose ID is in column, which must be
+// qualified with its table.
+func readableTopicClause(column string, p Principal) (string, []interface{}) {
+	clause := `(NOT EXISTS (SELECT 1 FROM topic_acl acl WHERE acl.topic_id = ` + column + `)
+		OR EXISTS (SELECT 1 FROM topic_acl acl WHERE acl.topic_id = ` + column + `
+			AND ((acl.kind = 'agent' AND acl.principal = ?) OR (acl.kind = 'role' AND acl.principal = ?))))`
+	return clause, []interface{}{p.Agent, p.Role}
+}
+
+// ACLDenial is an operation refused by a topic ACL.
+type ACLDenial struct {
+	ID        int64
+	TopicID   int64
+	Agent     string
+	Role      string
+	Operation string // e.g. "read", "post", "set_acl"
+	CreatedAt string
+}
+
+// RecordACLDenial records that p was refused an operation on a topic.
+func (db *DB) RecordACLDenial(topicID int64, p Principal, operation string) error {
+	_, err := db.Exec(
+		"INSERT INTO acl_denials (topic_id, agent, role, operation) VALUES (?, ?, ?, ?)",
+		topicID, p.Agent, p.Role, operation,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record ACL denial: %w", err)
+	}
+	return nil
+}
+
+// ListACLDenials retrieves refused operations, newest first, optionally
+// only for one topic (topicID 0 = all topics).
+func (db *DB) ListACLDenials(topicID int64, limit int) ([]ACLDenial, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := "SELECT id, topic_id, agent, role, operation, created_at FROM acl_denials"
+	var args []interface{}
+	if topicID > 0 {
+		query += " WHERE topic_id = ?"
+		args = append(args, topicID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ACL denials: %w", err)
+	}
+	defer rows.Close()
+
+	var denials []ACLDenial
+	for rows.Next() {
+		var d ACLDenial
+		if err := rows.Scan(&d.ID, &d.TopicID, &d.Agent, &d.Role, &d.Operation, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ACL denial: %w", err)
+		}
+		denials = append(denials, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ACL denials: %w", err)
+	}
+
+	return denials, nil
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Sources of audit events.
+const (
+	AuditSourceMCP = "mcp" // Tool call
+	AuditSourceCLI = "cli" // agent-hub command
+	AuditSourceTUI = "tui" // Dashboard action
+)
+
+// Results of audited actions.
+const (
+	AuditOK    = "ok"
+	AuditError = "error"
+)
+
+// DefaultAuditRetentionDays is how long audit events are kept by default.
+const DefaultAuditRetentionDays = 90
+
+// maxAuditDetail caps the error text kept with a failed action.
+const maxAuditDetail = 200
+
+// AuditEvent is a recorded hub mutation.
+type AuditEvent struct {
+	ID        int64
+	Actor     string // Agent, dashboard operator or OS user
+	Session   string // MCP session ID ("" outside MCP)
+	Source    string // AuditSourceMCP, AuditSourceCLI or AuditSourceTUI
+	Action    string // Tool name, or e.g. "token create" for commands
+	ArgsHash  string // SHA-256 of the JSON-encoded arguments
+	Result    string // AuditOK or AuditError
+	Detail    string // Error message of a failed action
+	CreatedAt string
+}
+
+// AuditQuery filters audit events. Empty fields match everything.
+type AuditQuery struct {
+	Actor   string
+	Session string
+	Source  string
+	Action  string
+	Result  string
+	Since   time.Time // Zero = no lower bound
+	Limit   int       // Default 50
+}
+
+// NewAuditEvent describes an action; err is its failure, if any.
+func NewAuditEvent(actor, session, source, action string, args interface{}, err error) AuditEvent {
+	e := AuditEvent{
+		Actor:    actor,
+		Session:  session,
+		Source:   source,
+		Action:   action,
+		ArgsHash: HashArgs(args),
+		Result:   AuditOK,
+	}
+	if err != nil {
+		e.Result = AuditError
+		e.Detail = err.Error()
+		if len(e.Detail) > maxAuditDetail {
+			e.Detail = e.Detail[:maxAuditDetail] + "..."
+		}
+	}
+	return e
+}
+
+// HashArgs returns the SHA-256 of the JSON encoding of args. Maps encode
+// with sorted keys, so equal arguments hash equally.
+func HashArgs(args interface{}) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		data = []byte(fmt.Sprint(args))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordAudit appends an event to the audit log.
+func (db *DB) RecordAudit(e AuditEvent) error {
+	_, err := db.Exec(
+		"INSERT INTO audit_events (actor, session, source, action, args_hash, result, detail) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		e.Actor, e.Session, e.Source, e.Action, e.ArgsHash, e.Result, e.Detail,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEvents retrieves audit events matching q, newest first.
+func (db *DB) ListAuditEvents(q AuditQuery) ([]AuditEvent, error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+
+	query := "SELECT id, actor, session, source, action, args_hash, result, detail, created_at FROM audit_events WHERE 1=1"
+	var args []interface{}
+	for column, value := range map[string]string{
+		"actor":   q.Actor,
+		"session": q.Session,
+		"source":  q.Source,
+		"action":  q.Action,
+		"result":  q.Result,
+	} {
+		if value != "" {
+			query += " AND " + column + " = ?"
+			args = append(args, value)
+		}
+	}
+	if !q.Since.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, q.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []AuditEvent
+	for rows.Next() {
+		var e AuditEvent
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Session, &e.Source, &e.Action, &e.ArgsHash, &e.Result, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// PruneAuditEvents deletes audit events older than retentionDays, which
+// must be at least 1, and returns the number deleted.
+func (db *DB) PruneAuditEvents(retentionDays int) (int64, error) {
+	if retentionDays < 1 {
+		return 0, fmt.Errorf("audit events must be kept for at least 1 day")
+	}
+
+	result, err := db.Exec("DELETE FROM audit_events WHERE created_at < datetime('now', ?)", fmt.Sprintf("-%d days", retentionDays))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit events: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"fmt"
+)
+
+// TopicUnread represents an agent's unread message count for one topic.
+type TopicUnread struct {
+	TopicID           int64
+	Title             string
+	UnreadCount       int64
+	LastReadMessageID int64
+}
+
+// AdvanceReadCursor moves an agent's read cursor for a topic forward to messageID.
+// The cursor never moves backwards.
+func (db *DB) AdvanceReadCursor(agent string, topicID, messageID int64) error {
+	_, err := db.Exec(
+		`INSERT INTO read_cursors (agent, topic_id, last_read_message_id, updated_at)
+		 VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(agent, topic_id) DO UPDATE SET
+		 last_read_message_id = MAX(last_read_message_id, excluded.last_read_message_id),
+		 updated_at = CURRENT_TIMESTAMP`,
+		agent, topicID, messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to advance read cursor: %w", err)
+	}
+	return nil
+}
+
+// MarkTopicRead moves an agent's read cursor to the latest message in a topic.
+// It returns the message ID the cursor now points at.
+func (db *DB) MarkTopicRead(agent string, topicID int64) (int64, error) {
+	var latestID int64
+	err := db.QueryRow(
+		"SELECT COALESCE(MAX(id), 0) FROM messages WHERE topic_id = ?",
+		topicID,
+	).Scan(&latestID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest message id: %w", err)
+	}
+
+	if err := db.AdvanceReadCursor(agent, topicID, latestID); err != nil {
+		return 0, err
+	}
+	return latestID, nil
+}
+
+// GetReadCursor returns the last message ID an agent has read in a topic (0 if none).
+func (db *DB) GetReadCursor(agent string, topicID int64) (int64, error) {
+	var messageID int64
+	err := db.QueryRow(
+		"SELECT COALESCE(MAX(last_read_message_id), 0) FROM read_cursors WHERE agent = ? AND topic_id = ?",
+		agent, topicID,
+	).Scan(&messageID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get read cursor: %w", err)
+	}
+	return messageID, nil
+}
+
+// CountUnreadByTopic counts, per topic, the messages from other agents that
+// are newer than the agent's read cursor. Topics without unread messages are
+// omitted, as are topics reader may not read (nil = none).
+func (db *DB) CountUnreadByTopic(agent string, reader *Principal) ([]TopicUnread, error) {
+	query := `SELECT t.id, t.title, COUNT(m.id), COALESCE(rc.last_read_message_id, 0)
+		 FROM topics t
+		 JOIN messages m ON m.topic_id = t.id
+		 LEFT JOIN read_cursors rc ON rc.topic_id = t.id AND rc.agent = ?
+		 WHERE m.id > COALESCE(rc.last_read_message_id, 0) AND m.sender != ?`
+	args := []interface{}{agent, agent}
+	if reader != nil {
+		clause, clauseArgs := readableTopicClause("t.id", *reader)
+		query += " AND " + clause
+		args = append(args, clauseArgs...)
+	}
+	query += " GROUP BY t.id ORDER BY t.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unread counts: %w", err)
+	}
+	defer rows.Close()
+
+	var unread []TopicUnread
+	for rows.Next() {
+		var u TopicUnread
+		if err := rows.Scan(&u.TopicID, &u.Title, &u.UnreadCount, &u.LastReadMessageID); err != nil {
+			return nil, fmt.Errorf("failed to scan unread count: %w", err)
+		}
+		unread = append(unread, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unread counts: %w", err)
+	}
+
+	return unread, nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -10,41 +11,76 @@ import (
 // DB wraps sql.DB with our schema.
 type DB struct {
 	*sql.DB
+	path string
 }
 
-// Open opens a SQLite database at the given path.
+// busyTimeoutMS is how long a connection waits for a lock held by another
+// process (e.g. a second `agent-hub serve` on the same file) before failing.
+const busyTimeoutMS = 5000
+
+// SQL expressions for timestamps with milliseconds, used where short leases
+// and TTLs must expire on time. sqlNowPlus takes a modifier such as
+// "+1.500 seconds".
+const (
+	sqlNow     = "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+	sqlNowPlus = "strftime('%Y-%m-%d %H:%M:%f', 'now', ?)"
+)
+
+// RequiredTables lists the tables a fully migrated database must contain.
+var RequiredTables = []string{"topics", "messages", "topic_summaries", "agent_presence", "hub_events", "read_cursors", "messages_fts", "summaries_fts", "direct_messages", "message_mentions", "orchestrator_state", "nudges", "sampling_requests", "sampling_workers", "summary_items", "tasks", "task_blockers", "locks", "kv_entries", "kv_history", "sessions", "api_tokens", "topic_acl", "acl_denials", "agent_keys", "redaction_rules", "redaction_events", "audit_events", "message_revisions"}
+
+// Open opens a SQLite database at the given path and applies any pending
+// schema migrations, backing up an existing database first.
 // If the file doesn't exist, it will be created.
 func Open(path string) (*DB, error) {
-	sqlDB, err := sql.Open("sqlite", path)
+	db, err := OpenUnmigrated(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Migrate(MigrateOptions{}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenUnmigrated opens a SQLite database without touching its schema.
+// It is used to inspect or explicitly migrate a database.
+func OpenUnmigrated(path string) (*DB, error) {
+	dsn := path
+	if !strings.Contains(dsn, "?") {
+		dsn = fmt.Sprintf("%s?_pragma=busy_timeout(%d)", dsn, busyTimeoutMS)
+	}
+
+	sqlDB, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// Every connection to ":memory:" is a separate database, so background
+	// readers (e.g. the event feed) must share the single connection.
+	if path == ":memory:" {
+		sqlDB.SetMaxOpenConns(1)
+	}
+
 	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db := &DB{DB: sqlDB}
+	db := &DB{DB: sqlDB, path: path}
 
 	// Enable WAL mode for better concurrent access
 	if _, err := db.Exec("PRAGMA journal_mode=WAL;"); err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
 	}
 
-	// Create schema
-	if err := db.CreateSchema(); err != nil {
-		return nil, fmt.Errorf("failed to create schema: %w", err)
-	}
-
 	return db, nil
 }
 
-// CreateSchema creates the database tables.
-func (db *DB) CreateSchema() error {
-	_, err := db.Exec(schemaSQL)
-	return err
-}
-
 // Close closes the database connection.
 func (db *DB) Close() error {
 	return db.DB.Close()
@@ -53,11 +89,10 @@ func (db *DB) Close() error {
 // CheckIntegrity verifies the database health and configuration.
 // Returns detailed information about which tables are missing.
 func (db *DB) CheckIntegrity() (map[string]bool, error) {
-	requiredTables := []string{"topics", "messages", "topic_summaries", "agent_presence"}
 	results := make(map[string]bool)
 	var missingTables []string
 
-	for _, table := range requiredTables {
+	for _, table := range RequiredTables {
 		var name string
 		err := db.QueryRow(
 			"SELECT name FROM sqlite_master WHERE type='table' AND name=?",
@@ -90,5 +125,24 @@ func (db *DB) CheckIntegrity() (map[string]bool, error) {
 		return results, fmt.Errorf("database is not in WAL mode (current: %s)", mode)
 	}
 
+	// Check schema version
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return results, err
+	}
+	if latest := LatestSchemaVersion(); version != latest {
+		return results, fmt.Errorf("schema version drift: database is at version %d, expected %d (run 'agent-hub migrate up')", version, latest)
+	}
+
 	return results, nil
 }
+
+// EmptyIfNil returns items, or an empty slice if items is nil, for lists
+// such as summary sections and ACL entries that JSON output should render
+// as [] rather than null.
+func EmptyIfNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
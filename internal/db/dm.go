@@ -0,0 +1,164 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DirectMessage represents a private message between two agents.
+type DirectMessage struct {
+	ID        int
+	Sender    string
+	Recipient string
+	Content   string
+	CreatedAt string
+	Read      bool // Whether the recipient has read the message
+}
+
+// DMQuery selects direct messages visible to an agent.
+type DMQuery struct {
+	Agent      string // Messages sent or received by this agent
+	With       string // Only the conversation with this agent (empty = all)
+	UnreadOnly bool   // Only messages to Agent that are still unread
+	Limit      int    // Maximum number of messages to return
+}
+
+// SendDirectMessage stores a direct message from sender to recipient.
+func (db *DB) SendDirectMessage(sender, recipient, content string) (int64, error) {
+	result, err := db.Exec(
+		"INSERT INTO direct_messages (sender, recipient, content) VALUES (?, ?, ?)",
+		sender, recipient, content,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send direct message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetDirectMessages retrieves direct messages matching q, newest first.
+// Only messages the agent sent or received are ever returned.
+func (db *DB) GetDirectMessages(q DMQuery) ([]DirectMessage, error) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+
+	query := `SELECT id, sender, recipient, content, created_at, read_at IS NOT NULL
+		FROM direct_messages WHERE (sender = ? OR recipient = ?)`
+	args := []interface{}{q.Agent, q.Agent}
+
+	if q.With != "" {
+		query += " AND (sender = ? OR recipient = ?)"
+		args = append(args, q.With, q.With)
+	}
+	if q.UnreadOnly {
+		query += " AND recipient = ? AND read_at IS NULL"
+		args = append(args, q.Agent)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, q.Limit)
+
+	return db.queryDirectMessages(query, args...)
+}
+
+// GetDirectMessagesSince retrieves messages to recipient with an ID greater
+// than afterID, oldest first.
+func (db *DB) GetDirectMessagesSince(recipient string, afterID int64, limit int) ([]DirectMessage, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	return db.queryDirectMessages(
+		`SELECT id, sender, recipient, content, created_at, read_at IS NOT NULL
+		 FROM direct_messages WHERE recipient = ? AND id > ?
+		 ORDER BY id ASC LIMIT ?`,
+		recipient, afterID, limit,
+	)
+}
+
+// queryDirectMessages runs a direct message query and scans the rows.
+func (db *DB) queryDirectMessages(query string, args ...interface{}) ([]DirectMessage, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query direct messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []DirectMessage
+	for rows.Next() {
+		var m DirectMessage
+		if err := rows.Scan(&m.ID, &m.Sender, &m.Recipient, &m.Content, &m.CreatedAt, &m.Read); err != nil {
+			return nil, fmt.Errorf("failed to scan direct message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating direct messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// MarkDirectMessagesRead marks the given messages to recipient as read.
+// IDs of messages addressed to someone else are ignored. Returns the number
+// of messages newly marked.
+func (db *DB) MarkDirectMessagesRead(recipient string, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := []interface{}{recipient}
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	result, err := db.Exec(
+		"UPDATE direct_messages SET read_at = CURRENT_TIMESTAMP WHERE recipient = ? AND read_at IS NULL AND id IN ("+strings.Join(placeholders, ", ")+")",
+		args...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark direct messages read: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return n, nil
+}
+
+// CountUnreadDirectMessages counts unread direct messages to recipient.
+func (db *DB) CountUnreadDirectMessages(recipient string) (int64, error) {
+	var count int64
+	err := db.QueryRow(
+		"SELECT COUNT(*) FROM direct_messages WHERE recipient = ? AND read_at IS NULL",
+		recipient,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread direct messages: %w", err)
+	}
+	return count, nil
+}
+
+// LatestDirectMessageID returns the ID of the most recent direct message to
+// recipient, or 0 if there are none.
+func (db *DB) LatestDirectMessageID(recipient string) (int64, error) {
+	var id int64
+	err := db.QueryRow(
+		"SELECT COALESCE(MAX(id), 0) FROM direct_messages WHERE recipient = ?",
+		recipient,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest direct message id: %w", err)
+	}
+	return id, nil
+}
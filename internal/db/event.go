@@ -0,0 +1,97 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultEventRetention is how long change feed events are kept by default.
+// Feeds only read events posted while they are running, so events are only
+// needed until every running feed has polled past them.
+const DefaultEventRetention = 24 * time.Hour
+
+// Event represents an entry in the hub_events change feed.
+// Events are written by triggers, so every process sharing the database
+// file observes posts made by any other process.
+type Event struct {
+	ID        int64
+	Kind      string
+	TopicID   int64
+	MessageID int64
+	Sender    string
+	Recipient string   // Set for direct messages; only the recipient is notified
+	Mentions  []string // Agents mentioned by a message event
+	Content   string
+	CreatedAt string
+}
+
+// LatestEventID returns the ID of the most recent event, or 0 if there are none.
+func (db *DB) LatestEventID() (int64, error) {
+	var id int64
+	err := db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM hub_events").Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest event id: %w", err)
+	}
+	return id, nil
+}
+
+// PruneEvents deletes change feed events older than retention, which must
+// be at least a minute, and returns the number deleted.
+func (db *DB) PruneEvents(retention time.Duration) (int64, error) {
+	if retention < time.Minute {
+		return 0, fmt.Errorf("events must be kept for at least a minute")
+	}
+
+	result, err := db.Exec("DELETE FROM hub_events WHERE created_at < datetime('now', ?)", fmt.Sprintf("-%d seconds", int64(retention.Seconds())))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return n, nil
+}
+
+// GetEventsSince retrieves events with an ID greater than afterID, oldest first.
+func (db *DB) GetEventsSince(afterID int64, limit int) ([]Event, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := db.Query(
+		`SELECT e.id, e.kind, COALESCE(e.topic_id, 0), COALESCE(e.message_id, 0),
+		        COALESCE(e.sender, ''), COALESCE(e.recipient, ''), COALESCE(m.content, ''), e.created_at,
+		        COALESCE((SELECT group_concat(mm.agent, char(10)) FROM message_mentions mm WHERE mm.message_id = m.id), '')
+		 FROM hub_events e
+		 LEFT JOIN messages m ON e.kind = 'message' AND m.id = e.message_id
+		 WHERE e.id > ?
+		 ORDER BY e.id ASC LIMIT ?`,
+		afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var mentions string
+		if err := rows.Scan(&e.ID, &e.Kind, &e.TopicID, &e.MessageID, &e.Sender, &e.Recipient, &e.Content, &e.CreatedAt, &mentions); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		if mentions != "" {
+			e.Mentions = strings.Split(mentions, "\n")
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+
+	return events, nil
+}
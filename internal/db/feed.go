@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultFeedInterval is how often an EventFeed polls the hub_events table.
+const DefaultFeedInterval = 500 * time.Millisecond
+
+// EventFeed tails the hub_events table and forwards new events to a Notifier.
+// Because events are stored in the shared database, a post made by one
+// process wakes the waiters of every process that uses the same file.
+// The feed only polls while at least one agent is subscribed.
+type EventFeed struct {
+	db       *DB
+	notifier *Notifier
+	interval time.Duration
+
+	mu     sync.Mutex
+	refs   int
+	cancel context.CancelFunc
+	done   chan struct{}
+	poke   chan struct{}
+}
+
+// NewEventFeed creates a feed that delivers database events to notifier.
+func NewEventFeed(database *DB, notifier *Notifier, interval time.Duration) *EventFeed {
+	if interval <= 0 {
+		interval = DefaultFeedInterval
+	}
+	return &EventFeed{
+		db:       database,
+		notifier: notifier,
+		interval: interval,
+		poke:     make(chan struct{}, 1),
+	}
+}
+
+// SubscribeOptions filters which events wake a subscriber.
+type SubscribeOptions struct {
+	MentionsOnly bool // Only wake for messages that mention the agent (and direct messages)
+}
+
+// Subscribe registers agentID with the notifier and starts tailing the
+// database if this is the first subscriber. The returned function must be
+// called to unsubscribe.
+func (f *EventFeed) Subscribe(agentID string, opts SubscribeOptions) (chan Notification, func(), error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.refs == 0 {
+		lastID, err := f.db.LatestEventID()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start event feed: %w", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		f.cancel = cancel
+		f.done = make(chan struct{})
+		go f.run(ctx, lastID, f.done)
+	}
+	f.refs++
+
+	ch := f.notifier.register(agentID, opts.MentionsOnly)
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			f.notifier.unregisterChannel(agentID, ch)
+			f.release()
+		})
+	}
+
+	return ch, unsubscribe, nil
+}
+
+// Poke asks the feed to poll immediately instead of waiting for the next tick.
+func (f *EventFeed) Poke() {
+	select {
+	case f.poke <- struct{}{}:
+	default:
+	}
+}
+
+// release drops one subscriber and stops the feed when none remain.
+func (f *EventFeed) release() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.refs--
+	if f.refs > 0 {
+		return
+	}
+	f.cancel()
+	<-f.done
+}
+
+// run polls for new events until ctx is cancelled.
+func (f *EventFeed) run(ctx context.Context, lastID int64, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-f.poke:
+		}
+
+		events, err := f.db.GetEventsSince(lastID, 100)
+		if err != nil {
+			log.Printf("Event feed poll failed: %v", err)
+			continue
+		}
+
+		for _, e := range events {
+			lastID = e.ID
+			notification := Notification{
+				AgentID:   e.Sender,
+				TopicID:   e.TopicID,
+				MessageID: e.MessageID,
+				EventID:   e.ID,
+				Mentions:  e.Mentions,
+				Message:   e.Content,
+				Timestamp: time.Now(),
+			}
+			if e.Recipient != "" {
+				// Direct messages only wake their recipient
+				f.notifier.Notify(e.Recipient, notification)
+			} else {
+				f.notifier.NotifyAll(notification)
+			}
+		}
+	}
+}
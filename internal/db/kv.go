@@ -0,0 +1,305 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxKVValueSize is the largest value the blackboard accepts, in bytes.
+const MaxKVValueSize = 64 * 1024
+
+// KVEntry is a live key on the blackboard.
+type KVEntry struct {
+	Namespace string
+	Key       string
+	Value     string
+	Version   int64 // Increases with every write to the key, including deletes
+	UpdatedBy string
+	CreatedAt string
+	UpdatedAt string
+	ExpiresAt string // Empty = never expires
+}
+
+// KVChange is one write to a key, as recorded in kv_history.
+type KVChange struct {
+	ID        int64
+	Namespace string
+	Key       string
+	Value     string
+	Version   int64
+	Deleted   bool
+	Author    string
+	ExpiresAt string
+	CreatedAt string
+}
+
+// KVWrite describes a write to the blackboard.
+type KVWrite struct {
+	Namespace       string
+	Key             string
+	Value           string
+	Author          string
+	TTL             time.Duration // 0 = never expires
+	Delete          bool          // Delete the key instead of setting it
+	ExpectedVersion *int64        // Only write if the key is at this version (0 = key must not exist)
+}
+
+const kvColumns = "namespace, key, value, version, updated_by, created_at, updated_at, COALESCE(expires_at, '')"
+
+// kvLive is an SQL condition that holds for keys that are neither deleted
+// nor expired.
+const kvLive = "(kv_entries.deleted = 0 AND (kv_entries.expires_at IS NULL OR kv_entries.expires_at > " + sqlNow + "))"
+
+// validateKVNames checks a namespace and key. Namespaces appear in
+// hub://kv/{namespace} URIs, so they can't contain URI delimiters.
+func validateKVNames(namespace, key string) error {
+	if strings.TrimSpace(namespace) == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+	if strings.ContainsAny(namespace, "/?#") {
+		return fmt.Errorf("namespace %q must not contain '/', '?' or '#'", namespace)
+	}
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+	return nil
+}
+
+// WriteKV sets or deletes a key. With ExpectedVersion set, the write is a
+// compare-and-swap: if the key is not at that version, nothing is written
+// and WriteKV returns false with the current entry (nil if the key doesn't
+// exist). On success it returns the written entry, or nil for deletes.
+func (db *DB) WriteKV(w KVWrite) (*KVEntry, bool, error) {
+	if err := validateKVNames(w.Namespace, w.Key); err != nil {
+		return nil, false, err
+	}
+	if len(w.Value) > MaxKVValueSize {
+		return nil, false, fmt.Errorf("value is %d bytes, more than the maximum of %d", len(w.Value), MaxKVValueSize)
+	}
+	if w.TTL < 0 {
+		return nil, false, fmt.Errorf("ttl must not be negative, got %v", w.TTL)
+	}
+
+	// A NULL modifier makes sqlNowPlus NULL, i.e. no expiry
+	var expiry sql.NullString
+	if w.TTL > 0 {
+		expiry = sql.NullString{String: fmt.Sprintf("+%.3f seconds", w.TTL.Seconds()), Valid: true}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Each case is a single statement, so concurrent writers can't both
+	// pass the version check
+	var row *sql.Row
+	switch {
+	case w.Delete:
+		query := "UPDATE kv_entries SET value = '', deleted = 1, version = version + 1, updated_by = ?, updated_at = " + sqlNow +
+			", expires_at = NULL WHERE namespace = ? AND key = ? AND " + kvLive
+		args := []interface{}{w.Author, w.Namespace, w.Key}
+		if w.ExpectedVersion != nil {
+			query += " AND version = ?"
+			args = append(args, *w.ExpectedVersion)
+		}
+		row = tx.QueryRow(query+" RETURNING "+kvColumns, args...)
+	case w.ExpectedVersion != nil && *w.ExpectedVersion > 0:
+		row = tx.QueryRow(
+			"UPDATE kv_entries SET value = ?, version = version + 1, updated_by = ?, updated_at = "+sqlNow+
+				", expires_at = "+sqlNowPlus+" WHERE namespace = ? AND key = ? AND version = ? AND "+kvLive+" RETURNING "+kvColumns,
+			w.Value, w.Author, expiry, w.Namespace, w.Key, *w.ExpectedVersion,
+		)
+	default:
+		// Create the key, reviving a deleted or expired one with a higher version
+		query := `INSERT INTO kv_entries (namespace, key, value, version, updated_by, created_at, updated_at, expires_at)
+			 VALUES (?, ?, ?, 1, ?, ` + sqlNow + `, ` + sqlNow + `, ` + sqlNowPlus + `)
+			 ON CONFLICT(namespace, key) DO UPDATE SET
+				created_at = CASE WHEN ` + kvLive + ` THEN kv_entries.created_at ELSE excluded.created_at END,
+				value = excluded.value, version = kv_entries.version + 1, deleted = 0,
+				updated_by = excluded.updated_by, updated_at = excluded.updated_at, expires_at = excluded.expires_at`
+		if w.ExpectedVersion != nil {
+			query += " WHERE NOT " + kvLive
+		}
+		row = tx.QueryRow(query+" RETURNING "+kvColumns, w.Namespace, w.Key, w.Value, w.Author, expiry)
+	}
+
+	entry, err := scanKVEntry(row)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		current, err := db.GetKV(w.Namespace, w.Key)
+		if err != nil {
+			return nil, false, err
+		}
+		if w.Delete && w.ExpectedVersion == nil && current == nil {
+			return nil, false, fmt.Errorf("key %q not found in namespace %q", w.Key, w.Namespace)
+		}
+		return current, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to write key: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO kv_history (namespace, key, value, version, deleted, author, expires_at, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?)`,
+		entry.Namespace, entry.Key, entry.Value, entry.Version, w.Delete, w.Author, entry.ExpiresAt, entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record key history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit key: %w", err)
+	}
+
+	if w.Delete {
+		return nil, true, nil
+	}
+	return entry, true, nil
+}
+
+// GetKV retrieves a live key. Returns nil if the key doesn't exist, was
+// deleted or has expired.
+func (db *DB) GetKV(namespace, key string) (*KVEntry, error) {
+	entry, err := scanKVEntry(db.QueryRow(
+		"SELECT "+kvColumns+" FROM kv_entries WHERE namespace = ? AND key = ? AND "+kvLive,
+		namespace, key,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	return entry, nil
+}
+
+// ListKV retrieves the live keys in a namespace whose names start with
+// prefix, ordered by key. An empty namespace lists every namespace.
+func (db *DB) ListKV(namespace, prefix string) ([]KVEntry, error) {
+	query := "SELECT " + kvColumns + " FROM kv_entries WHERE " + kvLive
+	var args []interface{}
+	if namespace != "" {
+		query += " AND namespace = ?"
+		args = append(args, namespace)
+	}
+	if prefix != "" {
+		query += " AND substr(key, 1, length(?)) = ?"
+		args = append(args, prefix, prefix)
+	}
+	query += " ORDER BY namespace, key"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keys: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []KVEntry
+	for rows.Next() {
+		entry, err := scanKVEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan key: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating keys: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetKVHistory retrieves the writes to a key, newest first. An empty key
+// returns the history of the whole namespace.
+func (db *DB) GetKVHistory(namespace, key string, limit int) ([]KVChange, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `SELECT id, namespace, key, value, version, deleted, author, COALESCE(expires_at, ''), created_at
+		FROM kv_history WHERE namespace = ?`
+	args := []interface{}{namespace}
+	if key != "" {
+		query += " AND key = ?"
+		args = append(args, key)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query key history: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []KVChange
+	for rows.Next() {
+		var c KVChange
+		if err := rows.Scan(&c.ID, &c.Namespace, &c.Key, &c.Value, &c.Version, &c.Deleted, &c.Author, &c.ExpiresAt, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan key history: %w", err)
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating key history: %w", err)
+	}
+
+	return changes, nil
+}
+
+// LatestKVChangeID returns the ID of the most recent write, or 0 if there
+// are none.
+func (db *DB) LatestKVChangeID() (int64, error) {
+	var id int64
+	if err := db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM kv_history").Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get latest key change id: %w", err)
+	}
+	return id, nil
+}
+
+// GetKVNamespacesChangedSince returns the namespaces written to after
+// afterID and the ID of the latest write.
+func (db *DB) GetKVNamespacesChangedSince(afterID int64) ([]string, int64, error) {
+	rows, err := db.Query(
+		"SELECT namespace, MAX(id) FROM kv_history WHERE id > ? GROUP BY namespace ORDER BY namespace",
+		afterID,
+	)
+	if err != nil {
+		return nil, afterID, fmt.Errorf("failed to query key changes: %w", err)
+	}
+	defer rows.Close()
+
+	var namespaces []string
+	lastID := afterID
+	for rows.Next() {
+		var namespace string
+		var id int64
+		if err := rows.Scan(&namespace, &id); err != nil {
+			return nil, afterID, fmt.Errorf("failed to scan key change: %w", err)
+		}
+		namespaces = append(namespaces, namespace)
+		lastID = max(lastID, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, afterID, fmt.Errorf("error iterating key changes: %w", err)
+	}
+
+	return namespaces, lastID, nil
+}
+
+// scanKVEntry scans a row selected with kvColumns.
+func scanKVEntry(row interface{ Scan(...any) error }) (*KVEntry, error) {
+	var e KVEntry
+	if err := row.Scan(&e.Namespace, &e.Key, &e.Value, &e.Version, &e.UpdatedBy, &e.CreatedAt, &e.UpdatedAt, &e.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
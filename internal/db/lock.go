@@ -0,0 +1,188 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lease limits for advisory locks.
+const (
+	DefaultLockTTL = 5 * time.Minute
+	MaxLockTTL     = time.Hour
+)
+
+// Lock is an advisory lease on a resource such as a file path.
+type Lock struct {
+	Resource     string
+	Holder       string
+	Note         string // What the holder is doing with the resource
+	AcquiredAt   string
+	ExpiresAt    string
+	ExpiresInSec int // Seconds until the lease expires (<= 0 = expired)
+}
+
+const lockColumns = "resource, holder, note, acquired_at, expires_at, CAST(ROUND((julianday(expires_at) - julianday('now')) * 86400) AS INTEGER)"
+
+// validateLock normalizes a resource name and checks the lease duration.
+func validateLock(resource string, ttl time.Duration) (string, string, error) {
+	resource = strings.TrimSpace(resource)
+	if resource == "" {
+		return "", "", fmt.Errorf("resource must not be empty")
+	}
+	if ttl <= 0 || ttl > MaxLockTTL {
+		return "", "", fmt.Errorf("lock ttl must be between 0 and %v, got %v", MaxLockTTL, ttl)
+	}
+	return resource, fmt.Sprintf("+%.3f seconds", ttl.Seconds()), nil
+}
+
+// AcquireLock takes the lock on resource for holder for ttl. It succeeds if
+// the resource is unlocked, its lease has expired, or holder already has it
+// (which extends the lease). Otherwise it returns the current lock and false.
+func (db *DB) AcquireLock(resource, holder, note string, ttl time.Duration) (*Lock, bool, error) {
+	resource, expiry, err := validateLock(resource, ttl)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for {
+		// A single upsert, so two agents can't both take an expired lock
+		lock, err := scanLock(db.QueryRow(
+			`INSERT INTO locks (resource, holder, note, acquired_at, expires_at)
+			 VALUES (?, ?, ?, `+sqlNow+`, `+sqlNowPlus+`)
+			 ON CONFLICT(resource) DO UPDATE SET
+				acquired_at = CASE WHEN locks.holder = excluded.holder AND locks.expires_at > excluded.acquired_at
+					THEN locks.acquired_at ELSE excluded.acquired_at END,
+				holder = excluded.holder, note = excluded.note, expires_at = excluded.expires_at
+			 WHERE locks.holder = excluded.holder OR locks.expires_at <= excluded.acquired_at
+			 RETURNING `+lockColumns,
+			resource, holder, note, expiry,
+		))
+		if err == nil {
+			return lock, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, false, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+
+		current, err := db.GetLock(resource)
+		if err != nil {
+			return nil, false, err
+		}
+		if current != nil {
+			return current, false, nil
+		}
+		// Released in the meantime; try again
+	}
+}
+
+// RenewLock extends holder's lease on resource by ttl from now. An expired
+// lease can't be renewed, since another agent may already have taken the
+// resource in the meantime; acquire it again instead.
+func (db *DB) RenewLock(resource, holder string, ttl time.Duration) (*Lock, error) {
+	resource, expiry, err := validateLock(resource, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := scanLock(db.QueryRow(
+		"UPDATE locks SET expires_at = "+sqlNowPlus+" WHERE resource = ? AND holder = ? AND expires_at > "+sqlNow+" RETURNING "+lockColumns,
+		expiry, resource, holder,
+	))
+	if err == sql.ErrNoRows {
+		return nil, db.notHeldError(resource)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew lock: %w", err)
+	}
+	return lock, nil
+}
+
+// ReleaseLock releases holder's lock on resource.
+func (db *DB) ReleaseLock(resource, holder string) error {
+	resource = strings.TrimSpace(resource)
+	result, err := db.Exec("DELETE FROM locks WHERE resource = ? AND holder = ?", resource, holder)
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return db.notHeldError(resource)
+	}
+	return nil
+}
+
+// notHeldError explains why the caller doesn't hold the lock on resource.
+func (db *DB) notHeldError(resource string) error {
+	current, err := db.GetLock(resource)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("resource %q is not locked", resource)
+	}
+	return fmt.Errorf("resource %q is locked by %s until %s", resource, current.Holder, current.ExpiresAt)
+}
+
+// GetLock retrieves the unexpired lock on resource. Returns nil if the
+// resource is not locked.
+func (db *DB) GetLock(resource string) (*Lock, error) {
+	lock, err := scanLock(db.QueryRow(
+		"SELECT "+lockColumns+" FROM locks WHERE resource = ? AND expires_at > "+sqlNow,
+		resource,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock: %w", err)
+	}
+	return lock, nil
+}
+
+// ListLocks retrieves unexpired locks, optionally only those of holder,
+// ordered by resource.
+func (db *DB) ListLocks(holder string) ([]Lock, error) {
+	query := "SELECT " + lockColumns + " FROM locks WHERE expires_at > " + sqlNow
+	var args []interface{}
+	if holder != "" {
+		query += " AND holder = ?"
+		args = append(args, holder)
+	}
+	query += " ORDER BY resource"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query locks: %w", err)
+	}
+	defer rows.Close()
+
+	var locks []Lock
+	for rows.Next() {
+		lock, err := scanLock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lock: %w", err)
+		}
+		locks = append(locks, *lock)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating locks: %w", err)
+	}
+
+	return locks, nil
+}
+
+// scanLock scans a row selected with lockColumns.
+func scanLock(row interface{ Scan(...any) error }) (*Lock, error) {
+	var l Lock
+	if err := row.Scan(&l.Resource, &l.Holder, &l.Note, &l.AcquiredAt, &l.ExpiresAt, &l.ExpiresInSec); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MentionSpan locates an @mention of a known agent within message content.
+type MentionSpan struct {
+	Start int    // Byte offset of the '@'
+	End   int    // Byte offset just past the name
+	Name  string // Agent name as registered
+}
+
+// Mention is a message that mentions an agent.
+type Mention struct {
+	MessageID  int
+	TopicID    int
+	TopicTitle string
+	Sender     string
+	Content    string
+	CreatedAt  string
+}
+
+// FindMentions locates @name mentions of the known agent names in content.
+// Names match case-insensitively; the longest known name wins, and a mention
+// must not be glued to surrounding word characters (so e-mail addresses and
+// "@claudette" do not mention "claude").
+func FindMentions(content string, known []string) []MentionSpan {
+	if len(known) == 0 || !strings.Contains(content, "@") {
+		return nil
+	}
+
+	// Longest first so "gemini-pro" is preferred over "gemini"
+	names := append([]string(nil), known...)
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	lower := strings.ToLower(content)
+	var spans []MentionSpan
+	for i := 0; i < len(content); i++ {
+		if content[i] != '@' || (i > 0 && isMentionChar(content[i-1])) {
+			continue
+		}
+		for _, name := range names {
+			end := i + 1 + len(name)
+			if name == "" || end > len(content) || lower[i+1:end] != strings.ToLower(name) {
+				continue
+			}
+			if end < len(content) && isMentionChar(content[end]) {
+				continue
+			}
+			spans = append(spans, MentionSpan{Start: i, End: end, Name: name})
+			i = end - 1
+			break
+		}
+	}
+	return spans
+}
+
+// ParseMentions returns the distinct known agent names mentioned in content,
+// in order of first appearance.
+func ParseMentions(content string, known []string) []string {
+	var mentioned []string
+	seen := make(map[string]bool)
+	for _, span := range FindMentions(content, known) {
+		if !seen[span.Name] {
+			seen[span.Name] = true
+			mentioned = append(mentioned, span.Name)
+		}
+	}
+	return mentioned
+}
+
+// isMentionChar reports whether c can continue an agent name. Dots are not
+// included so that "@claude." at the end of a sentence still matches.
+func isMentionChar(c byte) bool {
+	return c == '_' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// ListAgentNames returns the names of all agents in agent_presence.
+func (db *DB) ListAgentNames() ([]string, error) {
+	rows, err := db.Query("SELECT name FROM agent_presence ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent names: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan agent name: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agent names: %w", err)
+	}
+
+	return names, nil
+}
+
+// GetMessageMentions returns the agents mentioned by a message.
+func (db *DB) GetMessageMentions(messageID int64) ([]string, error) {
+	rows, err := db.Query("SELECT agent FROM message_mentions WHERE message_id = ? ORDER BY rowid", messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mentions: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []string
+	for rows.Next() {
+		var agent string
+		if err := rows.Scan(&agent); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		agents = append(agents, agent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %w", err)
+	}
+
+	return agents, nil
+}
+
+// GetUnreadMentions returns messages mentioning agent that are newer than the
+// agent's read cursor in their topic, newest first. Mentions in topics reader
+// may not read are omitted (nil = none).
+func (db *DB) GetUnreadMentions(agent string, reader *Principal, limit int) ([]Mention, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := `SELECT m.id, m.topic_id, t.title, m.sender, m.content, m.created_at
+		 FROM message_mentions mm
+		 JOIN messages m ON m.id = mm.message_id
+		 JOIN topics t ON t.id = m.topic_id
+		 LEFT JOIN read_cursors rc ON rc.topic_id = m.topic_id AND rc.agent = ?
+		 WHERE mm.agent = ? AND m.sender != ? AND m.id > COALESCE(rc.last_read_message_id, 0)`
+	args := []interface{}{agent, agent, agent}
+	if reader != nil {
+		clause, clauseArgs := readableTopicClause("m.topic_id", *reader)
+		query += " AND " + clause
+		args = append(args, clauseArgs...)
+	}
+	query += " ORDER BY m.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unread mentions: %w", err)
+	}
+	defer rows.Close()
+
+	var mentions []Mention
+	for rows.Next() {
+		var m Mention
+		if err := rows.Scan(&m.MessageID, &m.TopicID, &m.TopicTitle, &m.Sender, &m.Content, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		mentions = append(mentions, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %w", err)
+	}
+
+	return mentions, nil
+}
@@ -1,23 +1,105 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // Message represents a message in a topic.
 type Message struct {
-	ID        int
-	TopicID   int
-	Sender    string
-	Content   string
-	CreatedAt string
+	ID           int
+	TopicID      int
+	Sender       string
+	Content      string
+	CreatedAt    string
+	ReplyTo      int    // ID of the message this replies to (0 = not a reply)
+	Signature    string `json:"-"` // Base64 ed25519 signature ("" = unsigned)
+	SigningKey   string `json:"-"` // Base64 public key that made Signature
+	Verification string // VerificationVerified, VerificationInvalid or VerificationUnsigned
+	EditedAt     string // When the content was last edited ("" = never)
+	EditedBy     string // Agent that last edited or deleted it ("" = never changed)
+	Deleted      bool   // Soft-deleted; Content is DeletedContent
+}
+
+// messageColumns selects a message aliased m for scanMessage, including
+// whether its signing key is active for its sender.
+const messageColumns = `m.id, m.topic_id, m.sender, m.content, m.created_at, COALESCE(m.reply_to, 0),
+	COALESCE(m.signature, ''), COALESCE(m.signing_key, ''),
+	EXISTS (SELECT 1 FROM agent_keys k WHERE k.agent = m.sender AND k.public_key = m.signing_key AND k.revoked_at IS NULL),
+	COALESCE(m.edited_at, ''), COALESCE(m.edited_by, ''), m.deleted_at IS NOT NULL`
+
+// scanMessage scans a row selected with messageColumns and verifies its
+// signature. Deleted messages get DeletedContent as their content.
+func scanMessage(row interface{ Scan(...any) error }, m *Message) error {
+	var keyActive bool
+	if err := row.Scan(&m.ID, &m.TopicID, &m.Sender, &m.Content, &m.CreatedAt, &m.ReplyTo,
+		&m.Signature, &m.SigningKey, &keyActive, &m.EditedAt, &m.EditedBy, &m.Deleted); err != nil {
+		return err
+	}
+	if m.Deleted {
+		m.Content = DeletedContent
+	}
+	m.Verification = m.verification(keyActive)
+	return nil
 }
 
 // PostMessage posts a message to a topic.
 func (db *DB) PostMessage(topicID int64, sender, content string) (int64, error) {
-	result, err := db.Exec(
-		"INSERT INTO messages (topic_id, sender, content) VALUES (?, ?, ?)",
-		topicID, sender, content,
+	return db.PostReply(topicID, sender, content, 0)
+}
+
+// PostReply posts a message to a topic as a reply to replyTo, which must be
+// a message in the same topic. A replyTo of 0 posts a top-level message.
+// @mentions of registered agents are recorded in the same transaction, so
+// anyone woken by the post already sees who was mentioned.
+func (db *DB) PostReply(topicID int64, sender, content string, replyTo int64) (int64, error) {
+	return db.PostSignedReply(topicID, sender, content, replyTo, "")
+}
+
+// PostSignedReply posts a message like PostReply with a signature made by
+// SignMessage. The signature must match an active signing key of sender;
+// an empty signature posts the message unsigned.
+func (db *DB) PostSignedReply(topicID int64, sender, content string, replyTo int64, signature string) (int64, error) {
+	var signatureValue, signingKey interface{}
+	if signature != "" {
+		key, err := db.findSigningKey(topicID, sender, content, replyTo, signature)
+		if err != nil {
+			return 0, fmt.Errorf("invalid signature: %w", err)
+		}
+		signatureValue, signingKey = signature, key
+	}
+
+	mentioned, err := db.mentionsIn(content)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var parent interface{}
+	if replyTo != 0 {
+		var parentTopic int64
+		err := tx.QueryRow("SELECT topic_id FROM messages WHERE id = ?", replyTo).Scan(&parentTopic)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("message %d not found", replyTo)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to look up message %d: %w", replyTo, err)
+		}
+		if parentTopic != topicID {
+			return 0, fmt.Errorf("message %d belongs to topic %d, not topic %d", replyTo, parentTopic, topicID)
+		}
+		parent = replyTo
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO messages (topic_id, sender, content, reply_to, signature, signing_key) VALUES (?, ?, ?, ?, ?, ?)",
+		topicID, sender, content, parent, signatureValue, signingKey,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("failed to post message: %w", err)
@@ -28,9 +110,44 @@ func (db *DB) PostMessage(topicID int64, sender, content string) (int64, error)
 		return 0, fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
+	for _, agent := range mentioned {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO message_mentions (message_id, agent) VALUES (?, ?)", id, agent); err != nil {
+			return 0, fmt.Errorf("failed to record mention: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit message: %w", err)
+	}
+
 	return id, nil
 }
 
+// mentionsIn returns the registered agents @mentioned in content.
+func (db *DB) mentionsIn(content string) ([]string, error) {
+	if !strings.Contains(content, "@") {
+		return nil, nil
+	}
+	names, err := db.ListAgentNames()
+	if err != nil {
+		return nil, err
+	}
+	return ParseMentions(content, names), nil
+}
+
+// GetMessage retrieves a single message. Returns nil if it does not exist.
+func (db *DB) GetMessage(messageID int64) (*Message, error) {
+	var m Message
+	err := scanMessage(db.QueryRow("SELECT "+messageColumns+" FROM messages m WHERE m.id = ?", messageID), &m)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	return &m, nil
+}
+
 // GetMessages retrieves recent messages from a topic.
 func (db *DB) GetMessages(topicID int64, limit int) ([]Message, error) {
 	if limit <= 0 {
@@ -38,7 +155,7 @@ func (db *DB) GetMessages(topicID int64, limit int) ([]Message, error) {
 	}
 
 	rows, err := db.Query(
-		"SELECT id, topic_id, sender, content, created_at FROM messages WHERE topic_id = ? ORDER BY id DESC LIMIT ?",
+		"SELECT "+messageColumns+" FROM messages m WHERE m.topic_id = ? ORDER BY m.id DESC LIMIT ?",
 		topicID, limit,
 	)
 	if err != nil {
@@ -49,7 +166,7 @@ func (db *DB) GetMessages(topicID int64, limit int) ([]Message, error) {
 	var messages []Message
 	for rows.Next() {
 		var m Message
-		if err := rows.Scan(&m.ID, &m.TopicID, &m.Sender, &m.Content, &m.CreatedAt); err != nil {
+		if err := scanMessage(rows, &m); err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
 		messages = append(messages, m)
@@ -62,17 +179,15 @@ func (db *DB) GetMessages(topicID int64, limit int) ([]Message, error) {
 	return messages, nil
 }
 
-// CountUnreadMessages counts messages since the agent's last check.
+// CountUnreadMessages counts messages from other agents that are newer than
+// the agent's read cursor in each topic.
 func (db *DB) CountUnreadMessages(agentName string) (int64, error) {
 	var count int64
 	err := db.QueryRow(
-		`SELECT COUNT(*) FROM messages 
-		 WHERE created_at > (
-			 SELECT COALESCE(last_check, '1970-01-01 00:00:00') 
-			 FROM agent_presence 
-			 WHERE name = ?
-		 )`,
-		agentName,
+		`SELECT COUNT(*) FROM messages m
+		 LEFT JOIN read_cursors rc ON rc.topic_id = m.topic_id AND rc.agent = ?
+		 WHERE m.id > COALESCE(rc.last_read_message_id, 0) AND m.sender != ?`,
+		agentName, agentName,
 	).Scan(&count)
 
 	if err != nil {
@@ -81,3 +196,77 @@ func (db *DB) CountUnreadMessages(agentName string) (int64, error) {
 
 	return count, nil
 }
+
+// MessageQuery selects messages newer than a cursor across topics.
+type MessageQuery struct {
+	AfterID       int64      // Only messages with an ID greater than this
+	TopicIDs      []int64    // Only messages in these topics (empty = all topics)
+	ExcludeSender string     // Skip messages from this sender (e.g. the caller)
+	Mention       string     // Only messages that mention this agent (see message_mentions)
+	Limit         int        // Maximum number of messages to return
+	Reader        *Principal // Only topics this principal may read (nil = all topics)
+}
+
+// GetMessagesSince retrieves messages matching q, oldest first.
+func (db *DB) GetMessagesSince(q MessageQuery) ([]Message, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	query := "SELECT " + messageColumns + " FROM messages m WHERE m.id > ?"
+	args := []interface{}{q.AfterID}
+
+	if len(q.TopicIDs) > 0 {
+		placeholders := make([]string, len(q.TopicIDs))
+		for i, id := range q.TopicIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		query += " AND m.topic_id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if q.ExcludeSender != "" {
+		query += " AND m.sender != ?"
+		args = append(args, q.ExcludeSender)
+	}
+	if q.Mention != "" {
+		query += " AND m.id IN (SELECT message_id FROM message_mentions WHERE agent = ?)"
+		args = append(args, q.Mention)
+	}
+	if q.Reader != nil {
+		clause, clauseArgs := readableTopicClause("m.topic_id", *q.Reader)
+		query += " AND " + clause
+		args = append(args, clauseArgs...)
+	}
+	query += " ORDER BY m.id ASC LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var m Message
+		if err := scanMessage(rows, &m); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// LatestMessageID returns the ID of the most recent message, or 0 if there are none.
+func (db *DB) LatestMessageID() (int64, error) {
+	var id int64
+	if err := db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM messages").Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get latest message id: %w", err)
+	}
+	return id, nil
+}
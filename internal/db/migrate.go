@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations are embedded SQL files named NNNN_description.sql and applied in
+// order. The applied version is tracked with PRAGMA user_version.
+//
+// Databases created before versioning existed report version 0 but may
+// already contain some of the early tables, so migrations up to 0004 use
+// IF NOT EXISTS and are safe to re-run. Later migrations run exactly once.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a single schema change.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrateOptions controls a migration run.
+type MigrateOptions struct {
+	DryRun   bool // Report pending migrations without applying them
+	NoBackup bool // Skip the backup copy taken before migrating
+}
+
+// MigrationResult describes a migration run.
+type MigrationResult struct {
+	From       int
+	To         int
+	Applied    []Migration // Migrations applied (or pending, for a dry run)
+	BackupPath string      // Backup written before migrating, if any
+}
+
+// Migrations returns all embedded migrations ordered by version.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		versionStr, description, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    description,
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be sequential: expected %d, got %d", i+1, m.Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// LatestSchemaVersion returns the schema version this binary migrates to.
+func LatestSchemaVersion() int {
+	migrations, err := Migrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the schema version recorded in the database.
+func (db *DB) SchemaVersion() (int, error) {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// PendingMigrations returns the migrations not yet applied to the database.
+func (db *DB) PendingMigrations() ([]Migration, error) {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if version > len(migrations) {
+		return nil, fmt.Errorf("database schema version %d is newer than this binary supports (%d)", version, len(migrations))
+	}
+	return migrations[version:], nil
+}
+
+// Migrate applies all pending migrations, each in its own transaction.
+// Unless disabled, a backup of an existing file database is written first.
+func (db *DB) Migrate(opts MigrateOptions) (*MigrationResult, error) {
+	from, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{From: from, To: from, Applied: pending}
+	if len(pending) == 0 || opts.DryRun {
+		if opts.DryRun && len(pending) > 0 {
+			result.To = pending[len(pending)-1].Version
+		}
+		return result, nil
+	}
+
+	if !opts.NoBackup {
+		backupPath, err := db.backupBeforeMigrate(from)
+		if err != nil {
+			return nil, err
+		}
+		result.BackupPath = backupPath
+	}
+
+	for _, m := range pending {
+		if err := db.applyMigration(m); err != nil {
+			return result, err
+		}
+		result.To = m.Version
+	}
+
+	return result, nil
+}
+
+// applyMigration runs one migration and records its version atomically.
+// The write lock is taken up front so that when several processes open a
+// database at once, only the first applies the migration and the others skip it.
+func (db *DB) applyMigration(m Migration) (err error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration %04d: %w", m.Version, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return fmt.Errorf("failed to begin migration %04d: %w", m.Version, err)
+	}
+	defer func() {
+		if err != nil {
+			conn.ExecContext(ctx, "ROLLBACK")
+		}
+	}()
+
+	var current int
+	if err := conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if current < m.Version {
+		if _, err := conn.ExecContext(ctx, m.SQL); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		// PRAGMA arguments cannot be bound parameters
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", m.Version)); err != nil {
+			return fmt.Errorf("failed to record schema version %d: %w", m.Version, err)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return fmt.Errorf("failed to commit migration %04d: %w", m.Version, err)
+	}
+	return nil
+}
+
+// backupBeforeMigrate copies a file database that already holds data next to
+// the original, returning the backup path ("" if no backup was needed).
+func (db *DB) backupBeforeMigrate(version int) (string, error) {
+	if db.path == "" || db.path == ":memory:" {
+		return "", nil
+	}
+
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		return "", fmt.Errorf("failed to inspect database: %w", err)
+	}
+	if tables == 0 {
+		return "", nil // Fresh database, nothing to lose
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.%s.bak", db.path, version, time.Now().Format("20060102-150405"))
+	if _, err := db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return "", fmt.Errorf("failed to back up database before migrating: %w", err)
+	}
+	return backupPath, nil
+}
@@ -1,6 +1,5 @@
-package db
+-- Core BBS tables: topics, messages, presence and summaries.
 
-const schemaSQL = `
 CREATE TABLE IF NOT EXISTS topics (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     title TEXT NOT NULL,
@@ -33,4 +32,3 @@ CREATE TABLE IF NOT EXISTS topic_summaries (
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY(topic_id) REFERENCES topics(id)
 );
-`
@@ -0,0 +1,17 @@
+-- Change feed written by triggers so that every process sharing the
+-- database can observe posts made by any other process.
+
+CREATE TABLE IF NOT EXISTS hub_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    topic_id INTEGER,
+    message_id INTEGER,
+    sender TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS messages_event_after_insert AFTER INSERT ON messages
+BEGIN
+    INSERT INTO hub_events (kind, topic_id, message_id, sender)
+    VALUES ('message', NEW.topic_id, NEW.id, NEW.sender);
+END;
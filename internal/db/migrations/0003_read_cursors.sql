@@ -0,0 +1,10 @@
+-- Per-agent, per-topic read position used for unread counts.
+
+CREATE TABLE IF NOT EXISTS read_cursors (
+    agent TEXT NOT NULL,
+    topic_id INTEGER NOT NULL,
+    last_read_message_id INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY(agent, topic_id),
+    FOREIGN KEY(topic_id) REFERENCES topics(id)
+);
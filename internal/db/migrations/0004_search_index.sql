@@ -0,0 +1,49 @@
+-- Full-text search indexes over messages and topic summaries.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+    content,
+    content='messages',
+    content_rowid='id'
+);
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_after_insert AFTER INSERT ON messages
+BEGIN
+    INSERT INTO messages_fts (rowid, content) VALUES (NEW.id, NEW.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_after_delete AFTER DELETE ON messages
+BEGIN
+    INSERT INTO messages_fts (messages_fts, rowid, content) VALUES ('delete', OLD.id, OLD.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_after_update AFTER UPDATE OF content ON messages
+BEGIN
+    INSERT INTO messages_fts (messages_fts, rowid, content) VALUES ('delete', OLD.id, OLD.content);
+    INSERT INTO messages_fts (rowid, content) VALUES (NEW.id, NEW.content);
+END;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS summaries_fts USING fts5(
+    summary_text,
+    content='topic_summaries',
+    content_rowid='id'
+);
+
+CREATE TRIGGER IF NOT EXISTS summaries_fts_after_insert AFTER INSERT ON topic_summaries
+BEGIN
+    INSERT INTO summaries_fts (rowid, summary_text) VALUES (NEW.id, NEW.summary_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS summaries_fts_after_delete AFTER DELETE ON topic_summaries
+BEGIN
+    INSERT INTO summaries_fts (summaries_fts, rowid, summary_text) VALUES ('delete', OLD.id, OLD.summary_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS summaries_fts_after_update AFTER UPDATE OF summary_text ON topic_summaries
+BEGIN
+    INSERT INTO summaries_fts (summaries_fts, rowid, summary_text) VALUES ('delete', OLD.id, OLD.summary_text);
+    INSERT INTO summaries_fts (rowid, summary_text) VALUES (NEW.id, NEW.summary_text);
+END;
+
+-- Index rows that existed before the search index was created.
+INSERT INTO messages_fts (messages_fts) VALUES ('rebuild');
+INSERT INTO summaries_fts (summaries_fts) VALUES ('rebuild');
@@ -0,0 +1,5 @@
+-- Threaded replies: a message may answer an earlier message in the same topic.
+
+ALTER TABLE messages ADD COLUMN reply_to INTEGER REFERENCES messages(id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_reply_to ON messages(reply_to);
@@ -0,0 +1,22 @@
+-- Private direct messages between agents. DM events carry a recipient so
+-- that only the recipient's waiters are woken.
+
+CREATE TABLE direct_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sender TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    read_at DATETIME
+);
+
+CREATE INDEX idx_direct_messages_recipient ON direct_messages(recipient, id);
+CREATE INDEX idx_direct_messages_sender ON direct_messages(sender, id);
+
+ALTER TABLE hub_events ADD COLUMN recipient TEXT;
+
+CREATE TRIGGER direct_messages_event_after_insert AFTER INSERT ON direct_messages
+BEGIN
+    INSERT INTO hub_events (kind, message_id, sender, recipient)
+    VALUES ('dm', NEW.id, NEW.sender, NEW.recipient);
+END;
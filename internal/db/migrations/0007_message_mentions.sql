@@ -0,0 +1,10 @@
+-- @mentions resolved against agent_presence when a message is posted.
+
+CREATE TABLE message_mentions (
+    message_id INTEGER NOT NULL REFERENCES messages(id),
+    agent TEXT NOT NULL COLLATE NOCASE,
+    PRIMARY KEY (message_id, agent)
+);
+
+CREATE INDEX idx_message_mentions_agent ON message_mentions(agent, message_id);
+
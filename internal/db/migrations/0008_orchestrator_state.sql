@@ -0,0 +1,9 @@
+-- Orchestrator progress per topic, so restarts resume where they left off.
+
+CREATE TABLE orchestrator_state (
+    topic_id INTEGER PRIMARY KEY REFERENCES topics(id),
+    last_seen_message_id INTEGER NOT NULL DEFAULT 0,
+    unsummarized_count INTEGER NOT NULL DEFAULT 0,
+    last_activity_at DATETIME,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
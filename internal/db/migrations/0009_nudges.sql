@@ -0,0 +1,12 @@
+-- Inactivity nudges posted by the orchestrator, one per stalled message.
+
+CREATE TABLE nudges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER NOT NULL REFERENCES topics(id),
+    message_id INTEGER NOT NULL REFERENCES messages(id),
+    agents TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_nudges_message ON nudges(message_id);
+CREATE INDEX idx_nudges_topic ON nudges(topic_id, id);
@@ -0,0 +1,27 @@
+-- Summaries produced through MCP sampling. The orchestrator queues prompts
+-- in sampling_requests; serve processes with sampling-capable clients
+-- advertise themselves in sampling_workers, claim requests and store the
+-- result.
+
+CREATE TABLE sampling_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompt TEXT NOT NULL,
+    system_prompt TEXT NOT NULL DEFAULT '',
+    max_tokens INTEGER NOT NULL DEFAULT 1024,
+    status TEXT NOT NULL DEFAULT 'pending',
+    worker TEXT,
+    client TEXT,
+    model TEXT,
+    result TEXT,
+    error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+CREATE INDEX idx_sampling_requests_status ON sampling_requests(status, id);
+
+CREATE TABLE sampling_workers (
+    name TEXT PRIMARY KEY,
+    clients INTEGER NOT NULL DEFAULT 0,
+    last_seen DATETIME DEFAULT CURRENT_TIMESTAMP
+);
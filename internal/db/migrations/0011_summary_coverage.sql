@@ -0,0 +1,14 @@
+-- Range of messages each summary covers, so the next summary starts where
+-- the previous one stopped.
+
+ALTER TABLE topic_summaries ADD COLUMN first_message_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE topic_summaries ADD COLUMN last_message_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE topic_summaries ADD COLUMN message_count INTEGER NOT NULL DEFAULT 0;
+
+-- Older summaries covered whatever had been posted when they were written.
+UPDATE topic_summaries SET last_message_id = COALESCE((
+    SELECT MAX(m.id) FROM messages m
+    WHERE m.topic_id = topic_summaries.topic_id
+      AND m.sender != 'orchestrator'
+      AND m.created_at <= topic_summaries.created_at
+), 0);
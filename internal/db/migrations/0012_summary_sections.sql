@@ -0,0 +1,15 @@
+-- Structured summaries: an overview plus decisions, action items, open
+-- questions and risks. summary_text keeps the rendered markdown post.
+
+ALTER TABLE topic_summaries ADD COLUMN overview TEXT;
+
+CREATE TABLE IF NOT EXISTS summary_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    summary_id INTEGER NOT NULL REFERENCES topic_summaries(id),
+    kind TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    owner TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_items_summary ON summary_items(summary_id, kind, position);
@@ -0,0 +1,30 @@
+-- Task board: work items agents create, claim and complete, optionally
+-- drafted by the orchestrator from summary action items.
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    topic_id INTEGER REFERENCES topics(id),
+    assignee TEXT,
+    status TEXT NOT NULL DEFAULT 'todo',
+    priority TEXT NOT NULL DEFAULT 'normal',
+    created_by TEXT NOT NULL,
+    summary_id INTEGER REFERENCES topic_summaries(id),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_topic ON tasks(topic_id, status);
+
+-- A task is blocked until every task it is blocked by is done or cancelled.
+CREATE TABLE IF NOT EXISTS task_blockers (
+    task_id INTEGER NOT NULL REFERENCES tasks(id),
+    blocker_id INTEGER NOT NULL REFERENCES tasks(id),
+    PRIMARY KEY(task_id, blocker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_blockers_blocker ON task_blockers(blocker_id);
@@ -0,0 +1,14 @@
+-- Advisory locks on files or other resources, held by an agent until they
+-- release them or the lease expires. Expired rows are taken over by the next
+-- acquirer.
+
+CREATE TABLE IF NOT EXISTS locks (
+    resource TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    acquired_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_locks_holder ON locks(holder);
+CREATE INDEX IF NOT EXISTS idx_locks_expires ON locks(expires_at);
@@ -0,0 +1,32 @@
+-- Shared key-value blackboard. Deleted keys keep their row (deleted = 1) so
+-- versions only ever increase and compare-and-swap can't be fooled by a key
+-- that was deleted and recreated.
+
+CREATE TABLE IF NOT EXISTS kv_entries (
+    namespace TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    updated_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    expires_at TEXT,
+    PRIMARY KEY(namespace, key)
+);
+
+-- Every write, with its author. The id doubles as a change feed for
+-- hub://kv/{namespace} update notifications.
+CREATE TABLE IF NOT EXISTS kv_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    namespace TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    author TEXT NOT NULL,
+    expires_at TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_kv_history_key ON kv_history(namespace, key, id);
@@ -0,0 +1,17 @@
+-- MCP client sessions of every `agent-hub serve` process and the agent
+-- identity each one registered. Transport session IDs (e.g. "stdio") are
+-- not unique across processes, so rows have their own ID.
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    agent TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT '',
+    client_name TEXT NOT NULL DEFAULT '',
+    client_version TEXT NOT NULL DEFAULT '',
+    connected_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    disconnected_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_session_id ON sessions(session_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_connected ON sessions(disconnected_at, id);
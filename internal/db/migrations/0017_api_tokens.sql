@@ -0,0 +1,17 @@
+-- Bearer tokens for the HTTP transports. Only a SHA-256 hash of each token
+-- is stored; the token itself is shown once when it is created. Each token
+-- is bound to the agent name and role its requests act as.
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    agent TEXT NOT NULL,
+    role TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME,
+    revoked_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_agent ON api_tokens(agent);
@@ -0,0 +1,26 @@
+-- Topic access control lists. A topic without entries is open to every
+-- agent; once it has any, only the listed agents and roles may use it.
+-- Entries name an agent or a role and grant reader, writer or owner access,
+-- each including the ones before it. Refused operations are recorded in
+-- acl_denials.
+
+CREATE TABLE IF NOT EXISTS topic_acl (
+    topic_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('agent', 'role')),
+    principal TEXT NOT NULL,
+    access TEXT NOT NULL CHECK (access IN ('reader', 'writer', 'owner')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (topic_id, kind, principal),
+    FOREIGN KEY (topic_id) REFERENCES topics(id)
+);
+
+CREATE TABLE IF NOT EXISTS acl_denials (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER NOT NULL,
+    agent TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT '',
+    operation TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_acl_denials_topic ON acl_denials(topic_id, id);
@@ -0,0 +1,16 @@
+-- Signed messages: agents may register ed25519 public keys, and each message
+-- stores the signature over it and the key that made it. A message is
+-- verified when its key is registered, unrevoked, for its sender.
+
+ALTER TABLE messages ADD COLUMN signature TEXT;
+ALTER TABLE messages ADD COLUMN signing_key TEXT;
+
+CREATE TABLE IF NOT EXISTS agent_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    agent TEXT NOT NULL,
+    public_key TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    revoked_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_keys_agent ON agent_keys(agent);
@@ -0,0 +1,26 @@
+-- Secret redaction. redaction_rules holds regex rules added on top of the
+-- built-in detectors; each masks or rejects what it matches. Every post or
+-- direct message that tripped a rule is recorded in redaction_events, without
+-- the secret itself (message_id is NULL for rejected ones).
+
+CREATE TABLE IF NOT EXISTS redaction_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL DEFAULT 'mask' CHECK (action IN ('mask', 'reject')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS redaction_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sender TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('post', 'dm')),
+    topic_id INTEGER,
+    message_id INTEGER,
+    rule TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject')),
+    matches INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_redaction_events_sender ON redaction_events(sender, id);
@@ -0,0 +1,30 @@
+-- Append-only audit log of hub mutations: MCP tool calls, CLI commands and
+-- dashboard actions. Arguments are kept as a hash, so message content and
+-- secrets stay out of the log. Rows can't be changed, and only rows older
+-- than a day can be deleted, by the retention policy (PruneAuditEvents).
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL,
+    session TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL CHECK (source IN ('mcp', 'cli', 'tui')),
+    action TEXT NOT NULL,
+    args_hash TEXT NOT NULL,
+    result TEXT NOT NULL CHECK (result IN ('ok', 'error')),
+    detail TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events(created_at);
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_early_delete BEFORE DELETE ON audit_events
+WHEN OLD.created_at > datetime('now', '-1 day')
+BEGIN
+    SELECT RAISE(ABORT, 'audit events younger than a day cannot be deleted');
+END;
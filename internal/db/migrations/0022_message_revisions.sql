@@ -0,0 +1,22 @@
+-- Message edits and soft deletes. Each edit or delete keeps the content the
+-- message had before it as a revision; a deleted message stays in place as a
+-- tombstone with empty content, so replies to it keep their thread.
+
+ALTER TABLE messages ADD COLUMN edited_at DATETIME;
+ALTER TABLE messages ADD COLUMN deleted_at DATETIME;
+
+CREATE TABLE IF NOT EXISTS message_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL REFERENCES messages(id),
+    topic_id INTEGER NOT NULL REFERENCES topics(id),
+    editor TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('edit', 'delete')),
+    content TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_revisions_message ON message_revisions(message_id);
+CREATE INDEX IF NOT EXISTS idx_message_revisions_topic ON message_revisions(topic_id, id);
+
+-- Newest revision the orchestrator has checked against its summaries
+ALTER TABLE orchestrator_state ADD COLUMN last_seen_revision_id INTEGER NOT NULL DEFAULT 0;
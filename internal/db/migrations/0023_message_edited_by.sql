@@ -0,0 +1,8 @@
+-- Who last edited or deleted each message, shown next to it. Earlier
+-- changes are filled in from the revision history.
+
+ALTER TABLE messages ADD COLUMN edited_by TEXT;
+
+UPDATE messages SET edited_by = (
+    SELECT r.editor FROM message_revisions r WHERE r.message_id = messages.id ORDER BY r.id DESC LIMIT 1
+) WHERE id IN (SELECT message_id FROM message_revisions);
@@ -0,0 +1,4 @@
+-- Topic a sampling request summarizes, so serve processes only ask clients
+-- whose agents may read it (NULL = not about a topic).
+
+ALTER TABLE sampling_requests ADD COLUMN topic_id INTEGER REFERENCES topics(id);
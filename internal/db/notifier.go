@@ -1,6 +1,7 @@
 package db
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
@@ -9,26 +10,37 @@ import (
 type Notification struct {
 	AgentID   string
 	TopicID   int64
+	MessageID int64
+	EventID   int64
+	Mentions  []string // Agents mentioned by the message
 	Message   string
 	Timestamp time.Time
 }
 
 // Notifier manages notification channels for agents waiting on new messages.
 type Notifier struct {
-	mu       sync.RWMutex
-	channels map[string]chan Notification
+	mu           sync.RWMutex
+	channels     map[string]chan Notification
+	mentionsOnly map[string]bool // Agents that only want messages mentioning them
 }
 
 // NewNotifier creates a new Notifier instance.
 func NewNotifier() *Notifier {
 	return &Notifier{
-		channels: make(map[string]chan Notification),
+		channels:     make(map[string]chan Notification),
+		mentionsOnly: make(map[string]bool),
 	}
 }
 
 // Register creates a notification channel for an agent.
 // Returns a channel that the agent can use to receive notifications.
 func (n *Notifier) Register(agentID string) chan Notification {
+	return n.register(agentID, false)
+}
+
+// register creates a notification channel for an agent. If mentionsOnly is
+// set, NotifyAll skips the agent unless the notification mentions it.
+func (n *Notifier) register(agentID string, mentionsOnly bool) chan Notification {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
@@ -39,6 +51,11 @@ func (n *Notifier) Register(agentID string) chan Notification {
 
 	ch := make(chan Notification, 1)
 	n.channels[agentID] = ch
+	if mentionsOnly {
+		n.mentionsOnly[agentID] = true
+	} else {
+		delete(n.mentionsOnly, agentID)
+	}
 	return ch
 }
 
@@ -50,6 +67,20 @@ func (n *Notifier) Unregister(agentID string) {
 	if ch, exists := n.channels[agentID]; exists {
 		close(ch)
 		delete(n.channels, agentID)
+		delete(n.mentionsOnly, agentID)
+	}
+}
+
+// unregisterChannel removes the notification channel for an agent only if it
+// is still ch, so a replaced waiter does not tear down its replacement.
+func (n *Notifier) unregisterChannel(agentID string, ch chan Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if current, exists := n.channels[agentID]; exists && current == ch {
+		close(ch)
+		delete(n.channels, agentID)
+		delete(n.mentionsOnly, agentID)
 	}
 }
 
@@ -67,18 +98,34 @@ func (n *Notifier) Notify(agentID string, notification Notification) {
 	}
 }
 
-// NotifyAll sends a notification to all registered agents.
+// NotifyAll sends a notification to all registered agents, except agents
+// waiting for mentions only that the notification does not mention.
 func (n *Notifier) NotifyAll(notification Notification) {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
-	for _, ch := range n.channels {
+	for agentID, ch := range n.channels {
+		if n.mentionsOnly[agentID] && !mentions(notification.Mentions, agentID) {
+			continue
+		}
 		select {
 		case ch <- notification:
 		default:
-			// Channel is full or not ready, skip notification
+			// A wake-up is already pending; waiters re-read the database
+			// when woken, so coalescing notifications loses nothing
+		}
+	}
+}
+
+// mentions reports whether agentID is in the mentioned list (case-insensitive,
+// matching the message_mentions collation).
+func mentions(mentioned []string, agentID string) bool {
+	for _, name := range mentioned {
+		if strings.EqualFold(name, agentID) {
+			return true
 		}
 	}
+	return false
 }
 
 // Wait waits for a notification or times out.
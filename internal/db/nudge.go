@@ -0,0 +1,67 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Nudge records a reminder the orchestrator posted for a stalled message.
+type Nudge struct {
+	ID        int
+	TopicID   int
+	MessageID int      // The message nobody replied to
+	Agents    []string // Agents named in the nudge
+	CreatedAt string
+}
+
+// RecordNudge records a nudge for messageID in a topic. Each message is
+// nudged at most once; recording a second nudge for it fails.
+func (db *DB) RecordNudge(topicID, messageID int64, agents []string) (int64, error) {
+	result, err := db.Exec(
+		"INSERT INTO nudges (topic_id, message_id, agents) VALUES (?, ?, ?)",
+		topicID, messageID, strings.Join(agents, ","),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record nudge: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return id, nil
+}
+
+// HasNudge reports whether a nudge was already recorded for messageID.
+func (db *DB) HasNudge(messageID int64) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM nudges WHERE message_id = ?)", messageID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check nudge: %w", err)
+	}
+	return exists, nil
+}
+
+// GetLatestNudge returns the most recent nudge in a topic, or nil if the
+// topic was never nudged.
+func (db *DB) GetLatestNudge(topicID int64) (*Nudge, error) {
+	var n Nudge
+	var agents string
+	err := db.QueryRow(
+		"SELECT id, topic_id, message_id, agents, created_at FROM nudges WHERE topic_id = ? ORDER BY id DESC LIMIT 1",
+		topicID,
+	).Scan(&n.ID, &n.TopicID, &n.MessageID, &agents, &n.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest nudge: %w", err)
+	}
+
+	if agents != "" {
+		n.Agents = strings.Split(agents, ",")
+	}
+	return &n, nil
+}
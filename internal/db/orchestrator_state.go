@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OrchestratorState is the orchestrator's persisted progress for one topic.
+type OrchestratorState struct {
+	TopicID            int64
+	LastSeenMessageID  int64     // Newest message the orchestrator has counted
+	LastSeenRevisionID int64     // Newest message revision checked against the summaries
+	UnsummarizedCount  int       // Messages counted since the last summary
+	LastActivityAt     time.Time // Time of the newest counted message (zero if unknown)
+}
+
+// GetOrchestratorStates returns the persisted state of every tracked topic.
+func (db *DB) GetOrchestratorStates() (map[int64]OrchestratorState, error) {
+	rows, err := db.Query(
+		"SELECT topic_id, last_seen_message_id, last_seen_revision_id, unsummarized_count, last_activity_at FROM orchestrator_state",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orchestrator state: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[int64]OrchestratorState)
+	for rows.Next() {
+		var s OrchestratorState
+		var lastActivity sql.NullTime
+		if err := rows.Scan(&s.TopicID, &s.LastSeenMessageID, &s.LastSeenRevisionID, &s.UnsummarizedCount, &lastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan orchestrator state: %w", err)
+		}
+		if lastActivity.Valid {
+			s.LastActivityAt = lastActivity.Time
+		}
+		states[s.TopicID] = s
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orchestrator state: %w", err)
+	}
+
+	return states, nil
+}
+
+// SaveOrchestratorState inserts or replaces the state of a topic.
+func (db *DB) SaveOrchestratorState(s OrchestratorState) error {
+	var lastActivity interface{}
+	if !s.LastActivityAt.IsZero() {
+		lastActivity = s.LastActivityAt.UTC()
+	}
+
+	_, err := db.Exec(
+		`INSERT INTO orchestrator_state (topic_id, last_seen_message_id, last_seen_revision_id, unsummarized_count, last_activity_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(topic_id) DO UPDATE SET
+		 last_seen_message_id = excluded.last_seen_message_id,
+		 last_seen_revision_id = excluded.last_seen_revision_id,
+		 unsummarized_count = excluded.unsummarized_count,
+		 last_activity_at = excluded.last_activity_at,
+		 updated_at = CURRENT_TIMESTAMP`,
+		s.TopicID, s.LastSeenMessageID, s.LastSeenRevisionID, s.UnsummarizedCount, lastActivity,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save orchestrator state: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,11 @@ import (
 	"fmt"
 )
 
+// OrchestratorAgent is the name the orchestrator posts summaries and nudges
+// under. It is reserved: agents can't register as it, so only the
+// orchestrator's posts carry it.
+const OrchestratorAgent = "orchestrator"
+
 // AgentPresence represents an agent's presence status.
 type AgentPresence struct {
 	Name      string
@@ -117,3 +122,27 @@ func (db *DB) ListAllAgentPresence() ([]AgentPresence, error) {
 
 	return presences, nil
 }
+
+// ListAgentsInTopic returns the names of agents whose current topic is topicID.
+func (db *DB) ListAgentsInTopic(topicID int64) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM agent_presence WHERE topic_id = ? ORDER BY name", topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agents in topic: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan agent name: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agents in topic: %w", err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,364 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Redaction actions.
+const (
+	RedactMask   = "mask"   // Replace the secret with a [REDACTED:<rule>] placeholder
+	RedactReject = "reject" // Refuse the whole post
+)
+
+// Sources of redaction events.
+const (
+	RedactionSourcePost = "post"
+	RedactionSourceDM   = "dm"
+)
+
+// RedactionRule is a regex rule added to the built-in secret detectors. If
+// the pattern has a group named "secret", only that group is masked.
+type RedactionRule struct {
+	ID        int64
+	Name      string
+	Pattern   string
+	Action    string // RedactMask or RedactReject
+	CreatedAt string
+}
+
+// Redaction is a rule that matched content.
+type Redaction struct {
+	Rule    string
+	Action  string
+	Matches int
+}
+
+// RedactionEvent records a post or direct message that tripped a rule. The
+// secret itself is never stored.
+type RedactionEvent struct {
+	ID        int64
+	Sender    string
+	Source    string // RedactionSourcePost or RedactionSourceDM
+	TopicID   int64  // 0 for direct messages
+	MessageID int64  // 0 if the content was rejected
+	Rule      string
+	Action    string
+	Matches   int
+	CreatedAt string
+}
+
+// Entropy detector: long base64/base62-ish tokens with more than
+// minSecretEntropy bits per character. Hex digests top out at 4 bits, so
+// commit and content hashes are left alone. Identifiers such as
+// "TestRun_WithRules2024" reach the threshold too, so digits must also be
+// scattered over several runs unless the entropy is very high.
+const (
+	HighEntropyRule      = "high-entropy"
+	minSecretLength      = 32
+	minSecretEntropy     = 4.0
+	minSecretDigitRuns   = 3
+	certainSecretEntropy = 4.6
+)
+
+// BuiltinRedactionRules are the detectors run on every post unless
+// redaction is off.
+var BuiltinRedactionRules = []RedactionRule{
+	{Name: "private-key", Pattern: `-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?(?:-----END [A-Z0-9 ]*PRIVATE KEY-----|$)`},
+	{Name: "jwt", Pattern: `\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`},
+	{Name: "aws-access-key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "aws-secret-key", Pattern: `(?i)aws_secret_access_key["']?\s*[=:]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`},
+	{Name: "gcp-api-key", Pattern: `\bAIza[0-9A-Za-z_-]{35}`},
+	{Name: "github-token", Pattern: `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`},
+	{Name: "slack-token", Pattern: `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{Name: "llm-api-key", Pattern: `\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`},
+	// .env lines; values starting with '[' are placeholders of earlier rules
+	{Name: "env-secret", Pattern: `(?m)^[ \t]*(?:export[ \t]+)?[A-Za-z0-9_]*(?i:secret|token|password|passwd|api_?key|private_key)[A-Za-z0-9_]*[ \t]*=[ \t]*["']?(?P<secret>[^\s"'\[][^\s"']{7,})`},
+}
+
+// Redactor masks secrets in message content.
+type Redactor struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	name   string
+	action string
+	re     *regexp.Regexp
+	filter func(string) bool // Extra check on each match (nil = none)
+}
+
+var entropyCandidate = regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+/=_-]{%d,}`, minSecretLength))
+
+// NewRedactor returns a redactor running rules, then the built-in detectors
+// with builtinAction ("" = built-ins off).
+func NewRedactor(builtinAction string, rules []RedactionRule) (*Redactor, error) {
+	r := &Redactor{}
+	for _, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		r.rules = append(r.rules, c)
+	}
+
+	if builtinAction == "" {
+		return r, nil
+	}
+	for _, rule := range BuiltinRedactionRules {
+		rule.Action = builtinAction
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		r.rules = append(r.rules, c)
+	}
+	r.rules = append(r.rules, compiledRule{
+		name:   HighEntropyRule,
+		action: builtinAction,
+		re:     entropyCandidate,
+		filter: isHighEntropy,
+	})
+	return r, nil
+}
+
+// compileRule validates a rule.
+func compileRule(rule RedactionRule) (compiledRule, error) {
+	if rule.Action != RedactMask && rule.Action != RedactReject {
+		return compiledRule{}, fmt.Errorf("rule %s: action must be %s or %s", rule.Name, RedactMask, RedactReject)
+	}
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("rule %s: invalid pattern: %w", rule.Name, err)
+	}
+	return compiledRule{name: rule.Name, action: rule.Action, re: re}, nil
+}
+
+// Redact masks the secrets in content and reports the rules that matched.
+// Content is to be refused if any of them rejects.
+func (r *Redactor) Redact(content string) (string, []Redaction) {
+	var redactions []Redaction
+	for _, rule := range r.rules {
+		masked, n := rule.apply(content)
+		if n > 0 {
+			content = masked
+			redactions = append(redactions, Redaction{Rule: rule.name, Action: rule.action, Matches: n})
+		}
+	}
+	return content, redactions
+}
+
+// Rejecting returns the names of the redactions that reject content.
+func Rejecting(redactions []Redaction) []string {
+	var names []string
+	for _, r := range redactions {
+		if r.Action == RedactReject {
+			names = append(names, r.Rule)
+		}
+	}
+	return names
+}
+
+// apply replaces the rule's matches (or their "secret" group) with a
+// placeholder and returns the number replaced.
+func (c compiledRule) apply(content string) (string, int) {
+	secret := c.re.SubexpIndex("secret")
+
+	var b strings.Builder
+	last, n := 0, 0
+	for _, m := range c.re.FindAllStringSubmatchIndex(content, -1) {
+		start, end := m[0], m[1]
+		if secret > 0 {
+			start, end = m[2*secret], m[2*secret+1]
+		}
+		if start < last || start == end || (c.filter != nil && !c.filter(content[start:end])) {
+			continue
+		}
+		b.WriteString(content[last:start])
+		b.WriteString("[REDACTED:" + c.name + "]")
+		last = end
+		n++
+	}
+	if n == 0 {
+		return content, 0
+	}
+	b.WriteString(content[last:])
+	return b.String(), n
+}
+
+// isHighEntropy reports whether s looks like a random token.
+func isHighEntropy(s string) bool {
+	var counts [256]int
+	var letters bool
+	digitRuns := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		counts[c]++
+		switch {
+		case c >= '0' && c <= '9':
+			if i == 0 || s[i-1] < '0' || s[i-1] > '9' {
+				digitRuns++
+			}
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			letters = true
+		}
+	}
+	if !letters || digitRuns == 0 {
+		return false
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		if n > 0 {
+			p := float64(n) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy > certainSecretEntropy || (entropy > minSecretEntropy && digitRuns >= minSecretDigitRuns)
+}
+
+// Redactor returns a redactor running the rules added with AddRedactionRule
+// and the built-in detectors with builtinAction ("" = built-ins off).
+func (db *DB) Redactor(builtinAction string) (*Redactor, error) {
+	rules, err := db.ListRedactionRules()
+	if err != nil {
+		return nil, err
+	}
+	return NewRedactor(builtinAction, rules)
+}
+
+// AddRedactionRule adds a regex rule to the built-in detectors.
+func (db *DB) AddRedactionRule(name, pattern, action string) (*RedactionRule, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("rule name must not be empty")
+	}
+	for _, builtin := range BuiltinRedactionRules {
+		if name == builtin.Name {
+			return nil, fmt.Errorf("%s is a built-in rule", name)
+		}
+	}
+	if name == HighEntropyRule {
+		return nil, fmt.Errorf("%s is a built-in rule", name)
+	}
+	if action == "" {
+		action = RedactMask
+	}
+	if _, err := compileRule(RedactionRule{Name: name, Pattern: pattern, Action: action}); err != nil {
+		return nil, err
+	}
+
+	var rule RedactionRule
+	err := db.QueryRow(
+		"INSERT INTO redaction_rules (name, pattern, action) VALUES (?, ?, ?) RETURNING id, name, pattern, action, created_at",
+		name, pattern, action,
+	).Scan(&rule.ID, &rule.Name, &rule.Pattern, &rule.Action, &rule.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add redaction rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListRedactionRules retrieves the added rules ordered by ID.
+func (db *DB) ListRedactionRules() ([]RedactionRule, error) {
+	rows, err := db.Query("SELECT id, name, pattern, action, created_at FROM redaction_rules ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query redaction rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []RedactionRule
+	for rows.Next() {
+		var r RedactionRule
+		if err := rows.Scan(&r.ID, &r.Name, &r.Pattern, &r.Action, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan redaction rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating redaction rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// RemoveRedactionRule removes an added rule by name.
+func (db *DB) RemoveRedactionRule(name string) error {
+	result, err := db.Exec("DELETE FROM redaction_rules WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to remove redaction rule: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("redaction rule %s not found", name)
+	}
+	return nil
+}
+
+// RecordRedactions records the rules that matched a post or direct message.
+// messageID is 0 if the content was rejected.
+func (db *DB) RecordRedactions(sender, source string, topicID, messageID int64, redactions []Redaction) error {
+	var topic, message interface{}
+	if topicID != 0 {
+		topic = topicID
+	}
+	if messageID != 0 {
+		message = messageID
+	}
+
+	for _, r := range redactions {
+		_, err := db.Exec(
+			"INSERT INTO redaction_events (sender, source, topic_id, message_id, rule, action, matches) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			sender, source, topic, message, r.Rule, r.Action, r.Matches,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record redaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListRedactionEvents retrieves redaction events, newest first, optionally
+// only for one sender.
+func (db *DB) ListRedactionEvents(sender string, limit int) ([]RedactionEvent, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := "SELECT id, sender, source, COALESCE(topic_id, 0), COALESCE(message_id, 0), rule, action, matches, created_at FROM redaction_events"
+	var args []interface{}
+	if sender != "" {
+		query += " WHERE sender = ?"
+		args = append(args, sender)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query redaction events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []RedactionEvent
+	for rows.Next() {
+		var e RedactionEvent
+		if err := rows.Scan(&e.ID, &e.Sender, &e.Source, &e.TopicID, &e.MessageID, &e.Rule, &e.Action, &e.Matches, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan redaction event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating redaction events: %w", err)
+	}
+
+	return events, nil
+}
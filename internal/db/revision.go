@@ -0,0 +1,211 @@
+package db
+
+import (
+	"fmt"
+)
+
+// RoleAdmin is the API token role that may edit and delete any agent's
+// messages. Everyone else may only change their own.
+const RoleAdmin = "admin"
+
+// DeletedContent is the content shown for a deleted message.
+const DeletedContent = "[deleted]"
+
+// Kinds of message revisions.
+const (
+	RevisionEdit   = "edit"
+	RevisionDelete = "delete"
+)
+
+// MessageRevision is the content a message had before it was edited or
+// deleted.
+type MessageRevision struct {
+	ID        int64
+	MessageID int64
+	TopicID   int64
+	Sender    string // Sender of the message
+	Editor    string // Agent that edited or deleted it
+	Action    string // RevisionEdit or RevisionDelete
+	Content   string // Content before the change
+	CreatedAt string
+}
+
+// RevisionQuery filters message revisions. Zero fields match everything.
+type RevisionQuery struct {
+	MessageID int64 // Only revisions of this message
+	TopicID   int64 // Only revisions of messages in this topic
+	AfterID   int64 // Only revisions with an ID greater than this
+	Limit     int   // Default 100
+}
+
+// CanChangeMessage reports whether p may edit or delete a message by
+// sender: its own messages, or anyone's as an admin. A role alone doesn't
+// make p an admin, since agents can claim any role when registering.
+func CanChangeMessage(p Principal, sender string) bool {
+	return p.Agent == sender || p.Admin
+}
+
+// EditMessage replaces the content of a message, keeping the old content
+// as a revision. Only the sender or an admin may edit a message, and
+// deleted messages can't be edited. A signature made by SignMessage over
+// the new content keeps the message signed; otherwise it becomes unsigned.
+// @mentions are recorded afresh for the new content.
+func (db *DB) EditMessage(messageID int64, editor Principal, content, signature string) error {
+	m, err := db.changeableMessage(messageID, editor, "edit")
+	if err != nil {
+		return err
+	}
+
+	var signatureValue, signingKey interface{}
+	if signature != "" {
+		key, err := db.findSigningKey(int64(m.TopicID), m.Sender, content, int64(m.ReplyTo), signature)
+		if err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		signatureValue, signingKey = signature, key
+	}
+
+	mentioned, err := db.mentionsIn(content)
+	if err != nil {
+		return err
+	}
+
+	return db.reviseMessage(m, editor.Agent, RevisionEdit,
+		"UPDATE messages SET content = ?, signature = ?, signing_key = ?, edited_at = CURRENT_TIMESTAMP, edited_by = ? WHERE id = ? AND deleted_at IS NULL",
+		[]interface{}{content, signatureValue, signingKey, editor.Agent, messageID}, mentioned)
+}
+
+// DeleteMessage soft-deletes a message: it stays in its topic as a
+// tombstone, so replies keep their thread, but its content is kept only as
+// a revision. Only the sender or an admin may delete a message.
+func (db *DB) DeleteMessage(messageID int64, editor Principal) error {
+	m, err := db.changeableMessage(messageID, editor, "delete")
+	if err != nil {
+		return err
+	}
+
+	return db.reviseMessage(m, editor.Agent, RevisionDelete,
+		"UPDATE messages SET content = '', signature = NULL, signing_key = NULL, deleted_at = CURRENT_TIMESTAMP, edited_by = ? WHERE id = ? AND deleted_at IS NULL",
+		[]interface{}{editor.Agent, messageID}, nil)
+}
+
+// changeableMessage loads a message that editor wants to edit or delete,
+// checking that they may.
+func (db *DB) changeableMessage(messageID int64, editor Principal, operation string) (*Message, error) {
+	m, err := db.GetMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("message %d not found", messageID)
+	}
+	if m.Deleted {
+		return nil, fmt.Errorf("message %d is deleted", messageID)
+	}
+	if !CanChangeMessage(editor, m.Sender) {
+		return nil, fmt.Errorf("%s may not %s message %d: only its sender %s or an admin can", editor.Agent, operation, messageID, m.Sender)
+	}
+	return m, nil
+}
+
+// reviseMessage records the current content of m as a revision and applies
+// update to it in one transaction, replacing its mentions with mentioned.
+func (db *DB) reviseMessage(m *Message, editor, action, update string, args []interface{}, mentioned []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Read the content inside the transaction, so a concurrent edit isn't lost
+	var content string
+	if err := tx.QueryRow("SELECT content FROM messages WHERE id = ?", m.ID).Scan(&content); err != nil {
+		return fmt.Errorf("failed to read message %d: %w", m.ID, err)
+	}
+
+	result, err := tx.Exec(update, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s message: %w", action, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("message %d is deleted", m.ID)
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO message_revisions (message_id, topic_id, editor, action, content) VALUES (?, ?, ?, ?, ?)",
+		m.ID, m.TopicID, editor, action, content,
+	); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM message_mentions WHERE message_id = ?", m.ID); err != nil {
+		return fmt.Errorf("failed to clear mentions: %w", err)
+	}
+	for _, agent := range mentioned {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO message_mentions (message_id, agent) VALUES (?, ?)", m.ID, agent); err != nil {
+			return fmt.Errorf("failed to record mention: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit revision: %w", err)
+	}
+	return nil
+}
+
+// GetRevisions retrieves message revisions matching q, oldest first.
+func (db *DB) GetRevisions(q RevisionQuery) ([]MessageRevision, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	query := `SELECT r.id, r.message_id, r.topic_id, m.sender, r.editor, r.action, r.content, r.created_at
+		FROM message_revisions r JOIN messages m ON m.id = r.message_id
+		WHERE r.id > ?`
+	args := []interface{}{q.AfterID}
+	if q.MessageID != 0 {
+		query += " AND r.message_id = ?"
+		args = append(args, q.MessageID)
+	}
+	if q.TopicID != 0 {
+		query += " AND r.topic_id = ?"
+		args = append(args, q.TopicID)
+	}
+	query += " ORDER BY r.id ASC LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []MessageRevision
+	for rows.Next() {
+		var r MessageRevision
+		if err := rows.Scan(&r.ID, &r.MessageID, &r.TopicID, &r.Sender, &r.Editor, &r.Action, &r.Content, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// LatestRevisionID returns the ID of the most recent revision, or 0 if
+// there are none.
+func (db *DB) LatestRevisionID() (int64, error) {
+	var id int64
+	if err := db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM message_revisions").Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get latest revision id: %w", err)
+	}
+	return id, nil
+}
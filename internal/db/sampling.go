@@ -0,0 +1,214 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Sampling request states.
+const (
+	SamplingPending = "pending" // Waiting for a serve process to claim it
+	SamplingClaimed = "claimed" // A serve process is asking a client
+	SamplingDone    = "done"    // Result is available
+	SamplingFailed  = "failed"  // No client produced a result
+)
+
+// SamplingWorkerTTL is how long a serve process counts as available for
+// sampling after its last heartbeat.
+const SamplingWorkerTTL = 15 * time.Second
+
+// SamplingRequest is a prompt queued for an MCP client's model.
+type SamplingRequest struct {
+	ID           int
+	TopicID      int64 // Topic the prompt is about (0 = none); only its readers may be asked
+	Prompt       string
+	SystemPrompt string
+	MaxTokens    int
+	Status       string
+	Worker       string // Serve process that claimed the request
+	Client       string // MCP client that produced the result
+	Model        string // Model reported by the client
+	Result       string
+	Error        string
+	CreatedAt    string
+}
+
+// CreateSamplingRequest queues a prompt about a topic (0 = none) for
+// sampling.
+func (db *DB) CreateSamplingRequest(topicID int64, prompt, systemPrompt string, maxTokens int) (int64, error) {
+	var topic interface{}
+	if topicID != 0 {
+		topic = topicID
+	}
+	result, err := db.Exec(
+		"INSERT INTO sampling_requests (topic_id, prompt, system_prompt, max_tokens) VALUES (?, ?, ?, ?)",
+		topic, prompt, systemPrompt, maxTokens,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create sampling request: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetSamplingRequest retrieves a sampling request by ID.
+func (db *DB) GetSamplingRequest(id int64) (*SamplingRequest, error) {
+	var r SamplingRequest
+	err := db.QueryRow(
+		`SELECT id, COALESCE(topic_id, 0), prompt, system_prompt, max_tokens, status, COALESCE(worker, ''), COALESCE(client, ''),
+		 COALESCE(model, ''), COALESCE(result, ''), COALESCE(error, ''), created_at
+		 FROM sampling_requests WHERE id = ?`,
+		id,
+	).Scan(&r.ID, &r.TopicID, &r.Prompt, &r.SystemPrompt, &r.MaxTokens, &r.Status, &r.Worker, &r.Client,
+		&r.Model, &r.Result, &r.Error, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sampling request %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sampling request: %w", err)
+	}
+	return &r, nil
+}
+
+// ClaimSamplingRequest atomically claims the oldest pending request for
+// worker. It returns nil if nothing is pending.
+func (db *DB) ClaimSamplingRequest(worker string) (*SamplingRequest, error) {
+	var r SamplingRequest
+	err := db.QueryRow(
+		`UPDATE sampling_requests SET status = ?, worker = ?
+		 WHERE id = (SELECT id FROM sampling_requests WHERE status = ? ORDER BY id LIMIT 1) AND status = ?
+		 RETURNING id, COALESCE(topic_id, 0), prompt, system_prompt, max_tokens`,
+		SamplingClaimed, worker, SamplingPending, SamplingPending,
+	).Scan(&r.ID, &r.TopicID, &r.Prompt, &r.SystemPrompt, &r.MaxTokens)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sampling request: %w", err)
+	}
+
+	r.Status = SamplingClaimed
+	r.Worker = worker
+	return &r, nil
+}
+
+// CompleteSamplingRequest stores the result of a claimed request. The
+// prompt, which quotes the topic, is cleared; the result is kept until the
+// requester clears it with ClearSamplingResult.
+func (db *DB) CompleteSamplingRequest(id int64, client, model, result string) error {
+	_, err := db.Exec(
+		"UPDATE sampling_requests SET status = ?, client = ?, model = ?, result = ?, prompt = '', completed_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		SamplingDone, client, model, result, id, SamplingClaimed,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete sampling request: %w", err)
+	}
+	return nil
+}
+
+// FailSamplingRequest marks a pending or claimed request as failed and
+// clears its prompt. Returns false if the request had already finished.
+func (db *DB) FailSamplingRequest(id int64, reason string) (bool, error) {
+	result, err := db.Exec(
+		"UPDATE sampling_requests SET status = ?, error = ?, prompt = '', completed_at = CURRENT_TIMESTAMP WHERE id = ? AND status IN (?, ?)",
+		SamplingFailed, reason, id, SamplingPending, SamplingClaimed,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to fail sampling request: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return n > 0, nil
+}
+
+// CancelPendingSamplingRequest fails a request that nobody has claimed yet
+// and clears its prompt. Returns false if a worker already claimed it.
+func (db *DB) CancelPendingSamplingRequest(id int64, reason string) (bool, error) {
+	result, err := db.Exec(
+		"UPDATE sampling_requests SET status = ?, error = ?, prompt = '', completed_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		SamplingFailed, reason, id, SamplingPending,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel sampling request: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ClearSamplingResult drops the result of a completed request once the
+// requester has read it.
+func (db *DB) ClearSamplingResult(id int64) error {
+	if _, err := db.Exec("UPDATE sampling_requests SET result = NULL WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to clear sampling result: %w", err)
+	}
+	return nil
+}
+
+// PruneSamplingRequests deletes sampling requests created more than
+// retention ago, which must be at least a minute, and returns the number
+// deleted.
+func (db *DB) PruneSamplingRequests(retention time.Duration) (int64, error) {
+	if retention < time.Minute {
+		return 0, fmt.Errorf("sampling requests must be kept for at least a minute")
+	}
+
+	result, err := db.Exec("DELETE FROM sampling_requests WHERE created_at < datetime('now', ?)", fmt.Sprintf("-%d seconds", int64(retention.Seconds())))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune sampling requests: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return n, nil
+}
+
+// UpdateSamplingWorker records that a serve process is alive and how many
+// sampling-capable clients it may ask.
+func (db *DB) UpdateSamplingWorker(name string, clients int) error {
+	_, err := db.Exec(
+		`INSERT INTO sampling_workers (name, clients, last_seen) VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(name) DO UPDATE SET clients = excluded.clients, last_seen = CURRENT_TIMESTAMP`,
+		name, clients,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sampling worker: %w", err)
+	}
+	return nil
+}
+
+// RemoveSamplingWorker removes a serve process that is shutting down.
+func (db *DB) RemoveSamplingWorker(name string) error {
+	if _, err := db.Exec("DELETE FROM sampling_workers WHERE name = ?", name); err != nil {
+		return fmt.Errorf("failed to remove sampling worker: %w", err)
+	}
+	return nil
+}
+
+// CountSamplingClients returns the number of sampling-capable clients
+// offered by serve processes seen within maxAge.
+func (db *DB) CountSamplingClients(maxAge time.Duration) (int, error) {
+	var count int
+	err := db.QueryRow(
+		"SELECT COALESCE(SUM(clients), 0) FROM sampling_workers WHERE last_seen >= datetime('now', ?)",
+		fmt.Sprintf("-%d seconds", int(maxAge.Seconds())),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sampling clients: %w", err)
+	}
+	return count, nil
+}
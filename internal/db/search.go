@@ -0,0 +1,145 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Snippet highlight markers used by SearchMessages. Guillemets are used
+// rather than markdown bold because message content is often markdown.
+const (
+	HighlightStart = "«"
+	HighlightEnd   = "»"
+)
+
+// SearchResult represents a message or summary matching a search query.
+type SearchResult struct {
+	Kind       string // "message" or "summary"
+	ID         int
+	TopicID    int
+	TopicTitle string
+	Sender     string
+	Snippet    string
+	CreatedAt  string
+}
+
+// SearchFilter narrows a full-text search.
+type SearchFilter struct {
+	Sender           string     // Only messages from this sender (excludes summaries)
+	TopicID          int64      // Only this topic (0 = all topics)
+	Since            time.Time  // Only results created at or after this time
+	Until            time.Time  // Only results created at or before this time
+	IncludeSummaries bool       // Also search topic summaries
+	Limit            int        // Maximum number of results
+	Reader           *Principal // Only topics this principal may read (nil = all topics)
+}
+
+// SearchMessages runs a full-text search over messages (and optionally topic
+// summaries), best matches first. Matched terms in snippets are wrapped in
+// HighlightStart/HighlightEnd.
+func (db *DB) SearchMessages(query string, filter SearchFilter) ([]SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	messageSQL := `SELECT 'message', m.id, m.topic_id, t.title, m.sender,
+		snippet(messages_fts, 0, ?, ?, '...', 16), m.created_at, bm25(messages_fts) AS rank
+		FROM messages_fts
+		JOIN messages m ON m.id = messages_fts.rowid
+		JOIN topics t ON t.id = m.topic_id
+		WHERE messages_fts MATCH ?`
+	args := []interface{}{HighlightStart, HighlightEnd, match}
+
+	if filter.Sender != "" {
+		messageSQL += " AND m.sender = ?"
+		args = append(args, filter.Sender)
+	}
+	messageSQL, args = appendSearchScope(messageSQL, args, "m", filter)
+
+	query = messageSQL
+	if filter.IncludeSummaries && filter.Sender == "" {
+		summarySQL := `SELECT 'summary', s.id, s.topic_id, t.title, 'orchestrator',
+			snippet(summaries_fts, 0, ?, ?, '...', 16), s.created_at, bm25(summaries_fts) AS rank
+			FROM summaries_fts
+			JOIN topic_summaries s ON s.id = summaries_fts.rowid
+			JOIN topics t ON t.id = s.topic_id
+			WHERE summaries_fts MATCH ?`
+		args = append(args, HighlightStart, HighlightEnd, match)
+		summarySQL, args = appendSearchScope(summarySQL, args, "s", filter)
+		query += " UNION ALL " + summarySQL
+	}
+	query += " ORDER BY rank LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var rank float64
+		if err := rows.Scan(&r.Kind, &r.ID, &r.TopicID, &r.TopicTitle, &r.Sender, &r.Snippet, &r.CreatedAt, &rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// appendSearchScope adds the topic, date and access filters for table alias.
+func appendSearchScope(query string, args []interface{}, alias string, filter SearchFilter) (string, []interface{}) {
+	if filter.TopicID > 0 {
+		query += " AND " + alias + ".topic_id = ?"
+		args = append(args, filter.TopicID)
+	}
+	if !filter.Since.IsZero() {
+		query += " AND " + alias + ".created_at >= ?"
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if !filter.Until.IsZero() {
+		query += " AND " + alias + ".created_at <= ?"
+		args = append(args, filter.Until.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if filter.Reader != nil {
+		clause, clauseArgs := readableTopicClause(alias+".topic_id", *filter.Reader)
+		query += " AND " + clause
+		args = append(args, clauseArgs...)
+	}
+	return query, args
+}
+
+// ftsQuery turns free text into an FTS5 query that matches all terms.
+// Each term is quoted so punctuation cannot break the query syntax;
+// a trailing '*' keeps prefix matching.
+func ftsQuery(text string) string {
+	var terms []string
+	for _, term := range strings.Fields(text) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+	return strings.Join(terms, " ")
+}
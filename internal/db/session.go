@@ -0,0 +1,120 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Session is an MCP client connection and the agent it registered as.
+type Session struct {
+	ID             int64
+	SessionID      string // Transport session ID
+	Agent          string // Empty until the client calls bbs_register_agent
+	Role           string
+	ClientName     string
+	ClientVersion  string
+	ConnectedAt    string
+	DisconnectedAt string // Empty while connected
+}
+
+const sessionColumns = "id, session_id, agent, role, client_name, client_version, connected_at, COALESCE(disconnected_at, '')"
+
+// OpenSession records a new client connection and returns its row ID.
+func (db *DB) OpenSession(sessionID string) (int64, error) {
+	result, err := db.Exec("INSERT INTO sessions (session_id) VALUES (?)", sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open session: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get session ID: %w", err)
+	}
+	return id, nil
+}
+
+// SetSessionClient records the client name and version a session sent in
+// its initialize request.
+func (db *DB) SetSessionClient(id int64, name, version string) error {
+	_, err := db.Exec("UPDATE sessions SET client_name = ?, client_version = ? WHERE id = ?", name, version, id)
+	if err != nil {
+		return fmt.Errorf("failed to update session client: %w", err)
+	}
+	return nil
+}
+
+// SetSessionAgent records the agent identity a session registered.
+func (db *DB) SetSessionAgent(id int64, agent, role string) error {
+	_, err := db.Exec("UPDATE sessions SET agent = ?, role = ? WHERE id = ?", agent, role, id)
+	if err != nil {
+		return fmt.Errorf("failed to update session agent: %w", err)
+	}
+	return nil
+}
+
+// CloseSession records that a session disconnected.
+func (db *DB) CloseSession(id int64) error {
+	_, err := db.Exec(
+		"UPDATE sessions SET disconnected_at = CURRENT_TIMESTAMP WHERE id = ? AND disconnected_at IS NULL",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to close session: %w", err)
+	}
+	return nil
+}
+
+// GetSession retrieves a session by row ID. Returns nil if not found.
+func (db *DB) GetSession(id int64) (*Session, error) {
+	session, err := scanSession(db.QueryRow("SELECT "+sessionColumns+" FROM sessions WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+// ListSessions retrieves sessions, newest first, optionally only those
+// still connected.
+func (db *DB) ListSessions(connectedOnly bool, limit int) ([]Session, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := "SELECT " + sessionColumns + " FROM sessions"
+	if connectedOnly {
+		query += " WHERE disconnected_at IS NULL"
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// scanSession scans a row selected with sessionColumns.
+func scanSession(row interface{ Scan(...any) error }) (*Session, error) {
+	var s Session
+	if err := row.Scan(&s.ID, &s.SessionID, &s.Agent, &s.Role, &s.ClientName, &s.ClientVersion, &s.ConnectedAt, &s.DisconnectedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Verification states of a message signature.
+const (
+	VerificationVerified = "verified" // Signed with an active key registered for the sender
+	VerificationInvalid  = "invalid"  // Signed, but the signature or key doesn't check out
+	VerificationUnsigned = "unsigned"
+)
+
+// SigningKey is an ed25519 public key registered for an agent.
+type SigningKey struct {
+	ID        int64
+	Agent     string
+	PublicKey string // Base64
+	CreatedAt string
+	RevokedAt string // Empty = active
+}
+
+const signingKeyColumns = "id, agent, public_key, created_at, COALESCE(revoked_at, '')"
+
+// GenerateSigningKey creates an ed25519 key pair and returns the private key
+// encoded for storage in an agent's config.
+func GenerateSigningKey() (string, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(private), nil
+}
+
+// ParseSigningKey decodes a private key made by GenerateSigningKey.
+func ParseSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid signing key")
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// PublicKeyString returns the encoded public key of a private key.
+func PublicKeyString(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// signedPayload is what a message signature covers. The topic, sender and
+// parent are included so a signature can't be replayed elsewhere.
+func signedPayload(topicID int64, sender, content string, replyTo int64) []byte {
+	return []byte(fmt.Sprintf("agent-hub-message-v1\n%d\n%s\n%d\n%s", topicID, sender, replyTo, content))
+}
+
+// SignMessage signs a message with key and returns the encoded signature to
+// post with it.
+func SignMessage(key ed25519.PrivateKey, topicID int64, sender, content string, replyTo int64) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, signedPayload(topicID, sender, content, replyTo)))
+}
+
+// verifySignature checks an encoded signature against an encoded public key.
+func verifySignature(publicKey, signature string, payload []byte) bool {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(key), payload, sig)
+}
+
+// verification returns the verification state of a scanned message.
+// keyActive tells whether its signing key is registered, unrevoked, for the
+// sender.
+func (m *Message) verification(keyActive bool) string {
+	switch {
+	case m.Signature == "":
+		return VerificationUnsigned
+	case keyActive && verifySignature(m.SigningKey, m.Signature,
+		signedPayload(int64(m.TopicID), m.Sender, m.Content, int64(m.ReplyTo))):
+		return VerificationVerified
+	default:
+		return VerificationInvalid
+	}
+}
+
+// RegisterSigningKey registers the public key of an agent's signing key.
+// Agents with an active key are signing agents.
+func (db *DB) RegisterSigningKey(agent, publicKey string) (*SigningKey, error) {
+	agent = strings.TrimSpace(agent)
+	if agent == "" {
+		return nil, fmt.Errorf("agent must not be empty")
+	}
+	if key, err := base64.StdEncoding.DecodeString(publicKey); err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	key, err := scanSigningKey(db.QueryRow(
+		"INSERT INTO agent_keys (agent, public_key) VALUES (?, ?) RETURNING "+signingKeyColumns,
+		agent, publicKey,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register signing key: %w", err)
+	}
+	return key, nil
+}
+
+// ListSigningKeys retrieves registered keys ordered by ID, optionally
+// including revoked ones.
+func (db *DB) ListSigningKeys(includeRevoked bool) ([]SigningKey, error) {
+	query := "SELECT " + signingKeyColumns + " FROM agent_keys"
+	if !includeRevoked {
+		query += " WHERE revoked_at IS NULL"
+	}
+	query += " ORDER BY id"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []SigningKey
+	for rows.Next() {
+		k, err := scanSigningKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, *k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeSigningKey revokes a key by ID. Messages signed with it are no
+// longer verified.
+func (db *DB) RevokeSigningKey(id int64) error {
+	result, err := db.Exec("UPDATE agent_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke signing key: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("signing key %d not found or already revoked", id)
+	}
+	return nil
+}
+
+// IsSigningAgent reports whether an agent has an active signing key.
+func (db *DB) IsSigningAgent(agent string) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM agent_keys WHERE agent = ? AND revoked_at IS NULL)", agent).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up signing keys: %w", err)
+	}
+	return exists, nil
+}
+
+// findSigningKey returns the sender's active key that made signature, or an
+// error if none did.
+func (db *DB) findSigningKey(topicID int64, sender, content string, replyTo int64, signature string) (string, error) {
+	rows, err := db.Query("SELECT public_key FROM agent_keys WHERE agent = ? AND revoked_at IS NULL", sender)
+	if err != nil {
+		return "", fmt.Errorf("failed to query signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return "", fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating signing keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return "", fmt.Errorf("%s has no registered signing key", sender)
+	}
+	payload := signedPayload(topicID, sender, content, replyTo)
+	for _, key := range keys {
+		if verifySignature(key, signature, payload) {
+			return key, nil
+		}
+	}
+	return "", fmt.Errorf("signature does not match any signing key of %s", sender)
+}
+
+// scanSigningKey scans a row selected with signingKeyColumns.
+func scanSigningKey(row interface{ Scan(...any) error }) (*SigningKey, error) {
+	var k SigningKey
+	if err := row.Scan(&k.ID, &k.Agent, &k.PublicKey, &k.CreatedAt, &k.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
@@ -3,22 +3,64 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+)
+
+// Kinds of summary_items rows.
+const (
+	SummaryDecision     = "decision"
+	SummaryActionItem   = "action_item"
+	SummaryOpenQuestion = "open_question"
+	SummaryRisk         = "risk"
 )
 
 // TopicSummary represents a summary of a topic.
 type TopicSummary struct {
-	ID          int
-	TopicID     int
-	SummaryText string
-	IsMock      bool
-	CreatedAt   string
+	ID             int
+	TopicID        int
+	SummaryText    string // Rendered markdown, as posted to the topic
+	IsMock         bool
+	FirstMessageID int64            // First message covered by this summary (0 = unknown)
+	LastMessageID  int64            // Last message covered; the next summary starts after it
+	MessageCount   int              // Number of messages summarized
+	Sections       *SummarySections // Structured content (nil = free-form summary_text only)
+	CreatedAt      string
+}
+
+// SummarySections is the structured content of a summary.
+type SummarySections struct {
+	Overview      string
+	Decisions     []string
+	ActionItems   []ActionItem
+	OpenQuestions []string
+	Risks         []string
 }
 
-// SaveSummary saves a summary for a topic.
-func (db *DB) SaveSummary(topicID int64, summaryText string, isMock bool) (int64, error) {
-	result, err := db.Exec(
-		"INSERT INTO topic_summaries (topic_id, summary_text, is_mock) VALUES (?, ?, ?)",
-		topicID, summaryText, isMock,
+// ActionItem is a task agreed on in a topic.
+type ActionItem struct {
+	Task  string
+	Owner string // Agent responsible (empty = unassigned)
+}
+
+const summaryColumns = "id, topic_id, summary_text, is_mock, first_message_id, last_message_id, message_count, overview, created_at"
+
+// SaveSummary saves a summary for a topic along with the messages it covers
+// and its structured sections, if any.
+func (db *DB) SaveSummary(s TopicSummary) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var overview sql.NullString
+	if s.Sections != nil {
+		overview = sql.NullString{String: s.Sections.Overview, Valid: true}
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO topic_summaries (topic_id, summary_text, is_mock, first_message_id, last_message_id, message_count, overview) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		s.TopicID, s.SummaryText, s.IsMock, s.FirstMessageID, s.LastMessageID, s.MessageCount, overview,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("failed to save summary: %w", err)
@@ -29,18 +71,74 @@ func (db *DB) SaveSummary(topicID int64, summaryText string, isMock bool) (int64
 		return 0, fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
+	if s.Sections != nil {
+		insert := func(kind string, position int, text, owner string) error {
+			_, err := tx.Exec(
+				"INSERT INTO summary_items (summary_id, kind, position, text, owner) VALUES (?, ?, ?, ?, ?)",
+				id, kind, position, text, owner,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to save summary item: %w", err)
+			}
+			return nil
+		}
+		for i, text := range s.Sections.Decisions {
+			if err := insert(SummaryDecision, i, text, ""); err != nil {
+				return 0, err
+			}
+		}
+		for i, item := range s.Sections.ActionItems {
+			if err := insert(SummaryActionItem, i, item.Task, item.Owner); err != nil {
+				return 0, err
+			}
+		}
+		for i, text := range s.Sections.OpenQuestions {
+			if err := insert(SummaryOpenQuestion, i, text, ""); err != nil {
+				return 0, err
+			}
+		}
+		for i, text := range s.Sections.Risks {
+			if err := insert(SummaryRisk, i, text, ""); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit summary: %w", err)
+	}
+
 	return id, nil
 }
 
 // GetLatestSummary retrieves the latest summary for a topic.
 func (db *DB) GetLatestSummary(topicID int64) (*TopicSummary, error) {
-	row := db.QueryRow(
-		"SELECT id, topic_id, summary_text, is_mock, created_at FROM topic_summaries WHERE topic_id = ? ORDER BY created_at DESC LIMIT 1",
-		topicID,
+	return db.getLatestSummary("SELECT "+summaryColumns+" FROM topic_summaries WHERE topic_id = ? ORDER BY created_at DESC, id DESC LIMIT 1", topicID)
+}
+
+// GetLatestLLMSummary retrieves the latest summary for a topic that was not
+// produced by the mock summarizer.
+func (db *DB) GetLatestLLMSummary(topicID int64) (*TopicSummary, error) {
+	return db.getLatestSummary("SELECT "+summaryColumns+" FROM topic_summaries WHERE topic_id = ? AND is_mock = 0 ORDER BY created_at DESC, id DESC LIMIT 1", topicID)
+}
+
+// GetLatestLLMSummaryBefore retrieves the latest LLM summary for a topic
+// that ends before messageID and was made after every edit and delete of
+// the messages it covers, i.e. the summary to continue from when
+// re-summarizing the topic from that message.
+func (db *DB) GetLatestLLMSummaryBefore(topicID, messageID int64) (*TopicSummary, error) {
+	return db.getLatestSummary(
+		`SELECT `+summaryColumns+` FROM topic_summaries s
+		 WHERE topic_id = ? AND is_mock = 0 AND last_message_id < ?
+		 AND NOT EXISTS (SELECT 1 FROM message_revisions r
+		                 WHERE r.topic_id = s.topic_id AND r.message_id <= s.last_message_id AND r.created_at >= s.created_at)
+		 ORDER BY created_at DESC, id DESC LIMIT 1`,
+		topicID, messageID,
 	)
+}
 
-	var s TopicSummary
-	err := row.Scan(&s.ID, &s.TopicID, &s.SummaryText, &s.IsMock, &s.CreatedAt)
+func (db *DB) getLatestSummary(query string, args ...interface{}) (*TopicSummary, error) {
+	s, err := scanSummary(db.QueryRow(query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No summary found
@@ -48,13 +146,17 @@ func (db *DB) GetLatestSummary(topicID int64) (*TopicSummary, error) {
 		return nil, fmt.Errorf("failed to get latest summary: %w", err)
 	}
 
-	return &s, nil
+	summaries := []TopicSummary{*s}
+	if err := db.loadSummaryItems(summaries); err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
 }
 
 // GetSummariesByTopic retrieves all summaries for a topic, ordered by most recent first.
 func (db *DB) GetSummariesByTopic(topicID int64) ([]TopicSummary, error) {
 	rows, err := db.Query(
-		"SELECT id, topic_id, summary_text, is_mock, created_at FROM topic_summaries WHERE topic_id = ? ORDER BY created_at DESC",
+		"SELECT "+summaryColumns+" FROM topic_summaries WHERE topic_id = ? ORDER BY created_at DESC, id DESC",
 		topicID,
 	)
 	if err != nil {
@@ -64,16 +166,84 @@ func (db *DB) GetSummariesByTopic(topicID int64) ([]TopicSummary, error) {
 
 	var summaries []TopicSummary
 	for rows.Next() {
-		var s TopicSummary
-		if err := rows.Scan(&s.ID, &s.TopicID, &s.SummaryText, &s.IsMock, &s.CreatedAt); err != nil {
+		s, err := scanSummary(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan summary: %w", err)
 		}
-		summaries = append(summaries, s)
+		summaries = append(summaries, *s)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating summaries: %w", err)
 	}
 
+	if err := db.loadSummaryItems(summaries); err != nil {
+		return nil, err
+	}
 	return summaries, nil
 }
+
+// scanSummary scans a row selected with summaryColumns.
+func scanSummary(row interface{ Scan(...any) error }) (*TopicSummary, error) {
+	var s TopicSummary
+	var overview sql.NullString
+	if err := row.Scan(&s.ID, &s.TopicID, &s.SummaryText, &s.IsMock,
+		&s.FirstMessageID, &s.LastMessageID, &s.MessageCount, &overview, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if overview.Valid {
+		s.Sections = &SummarySections{Overview: overview.String}
+	}
+	return &s, nil
+}
+
+// loadSummaryItems fills in the sections of structured summaries.
+func (db *DB) loadSummaryItems(summaries []TopicSummary) error {
+	byID := make(map[int]*SummarySections)
+	var ids []interface{}
+	var placeholders []string
+	for i := range summaries {
+		if summaries[i].Sections != nil {
+			byID[summaries[i].ID] = summaries[i].Sections
+			ids = append(ids, summaries[i].ID)
+			placeholders = append(placeholders, "?")
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(
+		"SELECT summary_id, kind, text, COALESCE(owner, '') FROM summary_items WHERE summary_id IN ("+
+			strings.Join(placeholders, ", ")+") ORDER BY summary_id, kind, position",
+		ids...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query summary items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var summaryID int
+		var kind, text, owner string
+		if err := rows.Scan(&summaryID, &kind, &text, &owner); err != nil {
+			return fmt.Errorf("failed to scan summary item: %w", err)
+		}
+		sections := byID[summaryID]
+		switch kind {
+		case SummaryDecision:
+			sections.Decisions = append(sections.Decisions, text)
+		case SummaryActionItem:
+			sections.ActionItems = append(sections.ActionItems, ActionItem{Task: text, Owner: owner})
+		case SummaryOpenQuestion:
+			sections.OpenQuestions = append(sections.OpenQuestions, text)
+		case SummaryRisk:
+			sections.Risks = append(sections.Risks, text)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating summary items: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Task statuses, in board order.
+const (
+	TaskDraft      = "draft"       // Proposed by the orchestrator, not accepted yet
+	TaskTodo       = "todo"        // Ready to be claimed
+	TaskInProgress = "in_progress" // Claimed by its assignee
+	TaskDone       = "done"
+	TaskCancelled  = "cancelled"
+)
+
+// TaskStatuses lists the valid task statuses in board order.
+var TaskStatuses = []string{TaskDraft, TaskTodo, TaskInProgress, TaskDone, TaskCancelled}
+
+// Task priorities.
+const (
+	TaskLow    = "low"
+	TaskNormal = "normal"
+	TaskHigh   = "high"
+	TaskUrgent = "urgent"
+)
+
+// TaskPriorities lists the valid task priorities, lowest first.
+var TaskPriorities = []string{TaskLow, TaskNormal, TaskHigh, TaskUrgent}
+
+// Task is a unit of work on the task board.
+type Task struct {
+	ID          int
+	Title       string
+	Description string
+	TopicID     int    // Topic the task belongs to (0 = none)
+	Assignee    string // Agent working on the task (empty = unassigned)
+	Status      string
+	Priority    string
+	BlockedBy   []int64 // Tasks that must be finished first
+	Blocked     bool    // Some task in BlockedBy is neither done nor cancelled
+	CreatedBy   string
+	SummaryID   int // Summary the task was drafted from (0 = created by an agent)
+	CreatedAt   string
+	UpdatedAt   string
+	CompletedAt string // When the task was done (empty = not done)
+}
+
+// TaskUpdate lists the fields of a task to change. Nil fields are kept.
+type TaskUpdate struct {
+	Title       *string
+	Description *string
+	Assignee    *string // "" unassigns the task
+	Status      *string
+	Priority    *string
+	BlockedBy   *[]int64 // Replaces the blockers; an empty list removes them
+}
+
+// TaskQuery selects tasks on the board.
+type TaskQuery struct {
+	Statuses []string   // Only tasks in these statuses (empty = all)
+	Assignee string     // Only tasks assigned to this agent
+	TopicID  int64      // Only tasks in this topic (0 = all topics)
+	Reader   *Principal // Only tasks without a topic or in topics this principal may read (nil = all)
+	Limit    int        // Maximum number of tasks to return
+}
+
+// unfinishedBlockers is an SQL condition that holds when the task in the
+// outer query has a blocker that is neither done nor cancelled.
+const unfinishedBlockers = `EXISTS (SELECT 1 FROM task_blockers b JOIN tasks blocker ON blocker.id = b.blocker_id
+	WHERE b.task_id = tasks.id AND blocker.status NOT IN ('done', 'cancelled'))`
+
+const taskColumns = `id, title, description, COALESCE(topic_id, 0), COALESCE(assignee, ''), status, priority,
+	created_by, COALESCE(summary_id, 0), created_at, updated_at, completed_at, ` + unfinishedBlockers
+
+// validateTask checks the status and priority of a task.
+func validateTask(status, priority string) error {
+	if !slices.Contains(TaskStatuses, status) {
+		return fmt.Errorf("invalid task status %q (want one of %s)", status, strings.Join(TaskStatuses, ", "))
+	}
+	if !slices.Contains(TaskPriorities, priority) {
+		return fmt.Errorf("invalid task priority %q (want one of %s)", priority, strings.Join(TaskPriorities, ", "))
+	}
+	return nil
+}
+
+// CreateTask adds a task to the board. Status defaults to TaskTodo and
+// priority to TaskNormal.
+func (db *DB) CreateTask(t Task) (int64, error) {
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		return 0, fmt.Errorf("task title must not be empty")
+	}
+	if t.Status == "" {
+		t.Status = TaskTodo
+	}
+	if t.Priority == "" {
+		t.Priority = TaskNormal
+	}
+	if err := validateTask(t.Status, t.Priority); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if t.TopicID != 0 {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM topics WHERE id = ?)", t.TopicID).Scan(&exists); err != nil {
+			return 0, fmt.Errorf("failed to check topic: %w", err)
+		}
+		if !exists {
+			return 0, fmt.Errorf("topic %d not found", t.TopicID)
+		}
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO tasks (title, description, topic_id, assignee, status, priority, created_by, summary_id, completed_at)
+		 VALUES (?, ?, NULLIF(?, 0), NULLIF(?, ''), ?, ?, ?, NULLIF(?, 0), CASE WHEN ? = 'done' THEN CURRENT_TIMESTAMP END)`,
+		t.Title, t.Description, t.TopicID, t.Assignee, t.Status, t.Priority, t.CreatedBy, t.SummaryID, t.Status,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err := setTaskBlockers(tx, id, t.BlockedBy); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit task: %w", err)
+	}
+
+	return id, nil
+}
+
+// CreateDraftTask adds a TaskDraft task unless the topic already has a task
+// with the same title (ignoring case). Returns 0 if the task already exists.
+func (db *DB) CreateDraftTask(t Task) (int64, error) {
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		return 0, fmt.Errorf("task title must not be empty")
+	}
+
+	result, err := db.Exec(
+		`INSERT INTO tasks (title, description, topic_id, assignee, status, created_by, summary_id)
+		 SELECT ?, ?, ?, NULLIF(?, ''), ?, ?, NULLIF(?, 0)
+		 WHERE NOT EXISTS (SELECT 1 FROM tasks WHERE topic_id = ? AND lower(title) = lower(?))`,
+		t.Title, t.Description, t.TopicID, t.Assignee, TaskDraft, t.CreatedBy, t.SummaryID, t.TopicID, t.Title,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create draft task: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return id, nil
+}
+
+// setTaskBlockers replaces the blockers of a task, rejecting unknown tasks
+// and dependency cycles.
+func setTaskBlockers(tx *sql.Tx, taskID int64, blockers []int64) error {
+	if _, err := tx.Exec("DELETE FROM task_blockers WHERE task_id = ?", taskID); err != nil {
+		return fmt.Errorf("failed to clear task blockers: %w", err)
+	}
+
+	for _, blocker := range blockers {
+		if blocker == taskID {
+			return fmt.Errorf("task %d cannot block itself", taskID)
+		}
+
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ?)", blocker).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check blocking task: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("blocking task %d not found", blocker)
+		}
+
+		// The blocker must not itself wait for this task, directly or not
+		var cycle bool
+		err := tx.QueryRow(
+			`WITH RECURSIVE deps(id) AS (
+				SELECT blocker_id FROM task_blockers WHERE task_id = ?
+				UNION SELECT b.blocker_id FROM task_blockers b JOIN deps ON b.task_id = deps.id
+			 )
+			 SELECT EXISTS (SELECT 1 FROM deps WHERE id = ?)`,
+			blocker, taskID,
+		).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("failed to check task dependencies: %w", err)
+		}
+		if cycle {
+			return fmt.Errorf("task %d already depends on task %d", blocker, taskID)
+		}
+
+		if _, err := tx.Exec("INSERT OR IGNORE INTO task_blockers (task_id, blocker_id) VALUES (?, ?)", taskID, blocker); err != nil {
+			return fmt.Errorf("failed to add task blocker: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTask retrieves a task by ID.
+func (db *DB) GetTask(id int64) (*Task, error) {
+	tasks, err := db.queryTasks("SELECT "+taskColumns+" FROM tasks WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task %d not found", id)
+	}
+	return &tasks[0], nil
+}
+
+// ClaimTask assigns an unfinished task to agent and starts it. It fails if
+// another agent has claimed the task or it is blocked.
+func (db *DB) ClaimTask(id int64, agent string) (*Task, error) {
+	result, err := db.Exec(
+		`UPDATE tasks SET assignee = ?, status = ?, updated_at = CURRENT_TIMESTAMP
+		 WHERE id = ? AND status IN (?, ?, ?) AND COALESCE(assignee, '') IN ('', ?) AND NOT `+unfinishedBlockers,
+		agent, TaskInProgress, id, TaskDraft, TaskTodo, TaskInProgress, agent,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	task, err := db.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return task, nil
+	}
+
+	switch {
+	case task.Status == TaskDone || task.Status == TaskCancelled:
+		return nil, fmt.Errorf("task %d is already %s", id, task.Status)
+	case task.Assignee != "" && task.Assignee != agent:
+		return nil, fmt.Errorf("task %d is already claimed by %s", id, task.Assignee)
+	default:
+		return nil, fmt.Errorf("task %d is blocked by unfinished tasks %v", id, task.BlockedBy)
+	}
+}
+
+// UpdateTask changes the fields set in u and returns the updated task.
+func (db *DB) UpdateTask(id int64, u TaskUpdate) (*Task, error) {
+	sets := []string{"updated_at = CURRENT_TIMESTAMP"}
+	var args []interface{}
+
+	if u.Title != nil {
+		title := strings.TrimSpace(*u.Title)
+		if title == "" {
+			return nil, fmt.Errorf("task title must not be empty")
+		}
+		sets = append(sets, "title = ?")
+		args = append(args, title)
+	}
+	if u.Description != nil {
+		sets = append(sets, "description = ?")
+		args = append(args, *u.Description)
+	}
+	if u.Assignee != nil {
+		sets = append(sets, "assignee = NULLIF(?, '')")
+		args = append(args, *u.Assignee)
+	}
+	if u.Status != nil {
+		if !slices.Contains(TaskStatuses, *u.Status) {
+			return nil, fmt.Errorf("invalid task status %q (want one of %s)", *u.Status, strings.Join(TaskStatuses, ", "))
+		}
+		sets = append(sets, "status = ?", "completed_at = CASE WHEN ? = 'done' THEN COALESCE(completed_at, CURRENT_TIMESTAMP) END")
+		args = append(args, *u.Status, *u.Status)
+	}
+	if u.Priority != nil {
+		if !slices.Contains(TaskPriorities, *u.Priority) {
+			return nil, fmt.Errorf("invalid task priority %q (want one of %s)", *u.Priority, strings.Join(TaskPriorities, ", "))
+		}
+		sets = append(sets, "priority = ?")
+		args = append(args, *u.Priority)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE tasks SET "+strings.Join(sets, ", ")+" WHERE id = ?", append(args, id)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("task %d not found", id)
+	}
+
+	if u.BlockedBy != nil {
+		if err := setTaskBlockers(tx, id, *u.BlockedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit task: %w", err)
+	}
+
+	return db.GetTask(id)
+}
+
+// ListTasks retrieves tasks matching q, most urgent first.
+func (db *DB) ListTasks(q TaskQuery) ([]Task, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	query := "SELECT " + taskColumns + " FROM tasks WHERE 1 = 1"
+	var args []interface{}
+
+	if len(q.Statuses) > 0 {
+		placeholders := make([]string, len(q.Statuses))
+		for i, status := range q.Statuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		query += " AND status IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if q.Assignee != "" {
+		query += " AND assignee = ?"
+		args = append(args, q.Assignee)
+	}
+	if q.TopicID != 0 {
+		query += " AND topic_id = ?"
+		args = append(args, q.TopicID)
+	}
+	if q.Reader != nil {
+		clause, clauseArgs := readableTopicClause("tasks.topic_id", *q.Reader)
+		query += " AND (tasks.topic_id IS NULL OR " + clause + ")"
+		args = append(args, clauseArgs...)
+	}
+	query += " ORDER BY CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 ELSE 3 END, id LIMIT ?"
+	args = append(args, q.Limit)
+
+	return db.queryTasks(query, args...)
+}
+
+// queryTasks runs a query selecting taskColumns and loads the blockers of
+// the tasks found.
+func (db *DB) queryTasks(query string, args ...interface{}) ([]Task, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	byID := make(map[int]*Task)
+	for rows.Next() {
+		var t Task
+		var completedAt sql.NullString
+		if err := rows.Scan(&t.ID, &t.Title, &t.Description, &t.TopicID, &t.Assignee, &t.Status, &t.Priority,
+			&t.CreatedBy, &t.SummaryID, &t.CreatedAt, &t.UpdatedAt, &completedAt, &t.Blocked); err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		t.CompletedAt = completedAt.String
+		tasks = append(tasks, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	ids := make([]interface{}, len(tasks))
+	placeholders := make([]string, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+		ids[i] = tasks[i].ID
+		placeholders[i] = "?"
+	}
+
+	blockerRows, err := db.Query(
+		"SELECT task_id, blocker_id FROM task_blockers WHERE task_id IN ("+strings.Join(placeholders, ", ")+") ORDER BY task_id, blocker_id",
+		ids...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task blockers: %w", err)
+	}
+	defer blockerRows.Close()
+
+	for blockerRows.Next() {
+		var taskID int
+		var blocker int64
+		if err := blockerRows.Scan(&taskID, &blocker); err != nil {
+			return nil, fmt.Errorf("failed to scan task blocker: %w", err)
+		}
+		byID[taskID].BlockedBy = append(byID[taskID].BlockedBy, blocker)
+	}
+
+	if err := blockerRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task blockers: %w", err)
+	}
+
+	return tasks, nil
+}
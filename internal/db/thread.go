@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// maxThreadMessages caps how many messages GetThread loads for one tree.
+const maxThreadMessages = 500
+
+// ThreadMessage is a message together with its replies, oldest first.
+type ThreadMessage struct {
+	Message
+	Replies []*ThreadMessage
+}
+
+// GetThread retrieves a message and the tree of replies beneath it.
+// Returns nil if the message does not exist.
+func (db *DB) GetThread(messageID int64) (*ThreadMessage, error) {
+	rows, err := db.Query(
+		`WITH RECURSIVE thread(id) AS (
+			SELECT id FROM messages WHERE id = ?
+			UNION
+			SELECT m.id FROM messages m JOIN thread t ON m.reply_to = t.id
+		)
+		SELECT `+messageColumns+`
+		FROM messages m JOIN thread t ON t.id = m.id
+		ORDER BY m.id ASC LIMIT ?`,
+		messageID, maxThreadMessages,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query thread: %w", err)
+	}
+	defer rows.Close()
+
+	// Replies always have larger IDs than their parents, so walking in ID
+	// order attaches every message after its parent has been seen.
+	nodes := make(map[int]*ThreadMessage)
+	var root *ThreadMessage
+	for rows.Next() {
+		node := &ThreadMessage{}
+		m := &node.Message
+		if err := scanMessage(rows, m); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		nodes[m.ID] = node
+		if int64(m.ID) == messageID {
+			root = node
+		} else if parent, ok := nodes[m.ReplyTo]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating thread: %w", err)
+	}
+
+	return root, nil
+}
+
+// GetThreadRootID follows reply_to links up from messageID and returns the
+// top-level message of its thread.
+func (db *DB) GetThreadRootID(messageID int64) (int64, error) {
+	var rootID int64
+	err := db.QueryRow(
+		`WITH RECURSIVE ancestors(id, reply_to) AS (
+			SELECT id, reply_to FROM messages WHERE id = ?
+			UNION
+			SELECT m.id, m.reply_to FROM messages m JOIN ancestors a ON m.id = a.reply_to
+		)
+		SELECT id FROM ancestors WHERE reply_to IS NULL`,
+		messageID,
+	).Scan(&rootID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("message %d not found", messageID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find thread root: %w", err)
+	}
+	return rootID, nil
+}
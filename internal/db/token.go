@@ -0,0 +1,148 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TokenPrefix starts every API token, so leaked tokens are easy to spot.
+const TokenPrefix = "ahub_"
+
+// tokenPrefixLen is how many leading characters of a token are kept in
+// clear text to identify it in listings.
+const tokenPrefixLen = len(TokenPrefix) + 6
+
+// APIToken is a bearer token for the HTTP transports, bound to an agent.
+type APIToken struct {
+	ID         int64
+	Prefix     string // First characters of the token
+	Agent      string
+	Role       string
+	Note       string
+	CreatedAt  string
+	LastUsedAt string // Empty = never used
+	RevokedAt  string // Empty = active
+}
+
+const tokenColumns = "id, prefix, agent, role, note, created_at, COALESCE(last_used_at, ''), COALESCE(revoked_at, '')"
+
+// hashToken returns the stored form of a token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken creates a token for agent and role. The returned token string
+// is not stored and can't be retrieved again.
+func (db *DB) CreateToken(agent, role, note string) (string, *APIToken, error) {
+	agent = strings.TrimSpace(agent)
+	role = strings.TrimSpace(role)
+	if agent == "" {
+		return "", nil, fmt.Errorf("agent must not be empty")
+	}
+	if role == "" {
+		return "", nil, fmt.Errorf("role must not be empty")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	created, err := scanToken(db.QueryRow(
+		"INSERT INTO api_tokens (token_hash, prefix, agent, role, note) VALUES (?, ?, ?, ?, ?) RETURNING "+tokenColumns,
+		hashToken(token), token[:tokenPrefixLen], agent, role, note,
+	))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create token: %w", err)
+	}
+	return token, created, nil
+}
+
+// AuthenticateToken looks up an active token and records its use. Returns
+// nil if the token is unknown or revoked.
+func (db *DB) AuthenticateToken(token string) (*APIToken, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, nil
+	}
+
+	// last_used_at is only rewritten once a minute to spare the writer lock
+	t, err := scanToken(db.QueryRow(
+		`UPDATE api_tokens SET last_used_at = CASE
+			WHEN last_used_at IS NULL OR last_used_at < datetime('now', '-1 minute') THEN CURRENT_TIMESTAMP
+			ELSE last_used_at END
+		 WHERE token_hash = ? AND revoked_at IS NULL RETURNING `+tokenColumns,
+		hashToken(token),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate token: %w", err)
+	}
+	return t, nil
+}
+
+// ListTokens retrieves tokens ordered by ID, optionally including revoked
+// ones.
+func (db *DB) ListTokens(includeRevoked bool) ([]APIToken, error) {
+	query := "SELECT " + tokenColumns + " FROM api_tokens"
+	if !includeRevoked {
+		query += " WHERE revoked_at IS NULL"
+	}
+	query += " ORDER BY id"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		tokens = append(tokens, *t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken revokes a token by ID. Requests with it are refused from then
+// on.
+func (db *DB) RevokeToken(id int64) error {
+	result, err := db.Exec("UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("token %d not found or already revoked", id)
+	}
+	return nil
+}
+
+// scanToken scans a row selected with tokenColumns.
+func scanToken(row interface{ Scan(...any) error }) (*APIToken, error) {
+	var t APIToken
+	if err := row.Scan(&t.ID, &t.Prefix, &t.Agent, &t.Role, &t.Note, &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Topic represents a discussion topic.
@@ -49,3 +51,133 @@ func (db *DB) ListTopics() ([]Topic, error) {
 
 	return topics, nil
 }
+
+// TopicStats represents a topic together with its activity information.
+type TopicStats struct {
+	ID             int
+	Title          string
+	CreatedAt      string
+	MessageCount   int
+	LastActivityAt string
+	LastSender     string
+	SummarySnippet string
+	UnreadCount    int
+}
+
+// TopicListOptions controls filtering and pagination for ListTopicStats.
+type TopicListOptions struct {
+	Agent       string     // Agent whose unread counts are reported (per read cursor)
+	TitleQuery  string     // Case-insensitive substring of the topic title
+	ActiveSince time.Time  // Only topics with a message at or after this time
+	HasUnread   bool       // Only topics with unread messages for Agent
+	Cursor      int64      // Only topics with an ID lower than this (0 = start)
+	Limit       int        // Maximum number of topics to return
+	Reader      *Principal // Only topics this principal may read (nil = all topics)
+}
+
+// summarySnippetLength is the maximum length (in runes) of a summary snippet.
+const summarySnippetLength = 160
+
+// ListTopicStats retrieves topics with activity statistics, newest first.
+// It returns the cursor for the next page, or 0 if there are no more topics.
+func (db *DB) ListTopicStats(opts TopicListOptions) ([]TopicStats, int64, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	if opts.Limit > 100 {
+		opts.Limit = 100
+	}
+
+	var where []string
+	var having []string
+	args := []interface{}{opts.Agent, opts.Agent}
+
+	if opts.Cursor > 0 {
+		where = append(where, "t.id < ?")
+		args = append(args, opts.Cursor)
+	}
+	if opts.TitleQuery != "" {
+		where = append(where, "t.title LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(opts.TitleQuery)+"%")
+	}
+	if opts.Reader != nil {
+		clause, clauseArgs := readableTopicClause("t.id", *opts.Reader)
+		where = append(where, clause)
+		args = append(args, clauseArgs...)
+	}
+
+	query := `SELECT t.id, t.title, t.created_at,
+		COUNT(m.id),
+		COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', MAX(m.created_at)), ''),
+		COALESCE((SELECT sender FROM messages WHERE topic_id = t.id ORDER BY id DESC LIMIT 1), ''),
+		COALESCE((SELECT summary_text FROM topic_summaries WHERE topic_id = t.id ORDER BY id DESC LIMIT 1), ''),
+		COALESCE(SUM(CASE WHEN m.id > COALESCE(rc.last_read_message_id, 0) AND m.sender != ?
+			THEN 1 ELSE 0 END), 0) AS unread
+		FROM topics t
+		LEFT JOIN read_cursors rc ON rc.topic_id = t.id AND rc.agent = ?
+		LEFT JOIN messages m ON m.topic_id = t.id`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " GROUP BY t.id"
+
+	if !opts.ActiveSince.IsZero() {
+		having = append(having, "MAX(m.created_at) >= ?")
+		args = append(args, opts.ActiveSince.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if opts.HasUnread {
+		having = append(having, "unread > 0")
+	}
+	if len(having) > 0 {
+		query += " HAVING " + strings.Join(having, " AND ")
+	}
+
+	// Fetch one extra row to find out whether another page exists
+	query += " ORDER BY t.id DESC LIMIT ?"
+	args = append(args, opts.Limit+1)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query topic stats: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []TopicStats
+	for rows.Next() {
+		var t TopicStats
+		if err := rows.Scan(&t.ID, &t.Title, &t.CreatedAt, &t.MessageCount, &t.LastActivityAt,
+			&t.LastSender, &t.SummarySnippet, &t.UnreadCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan topic stats: %w", err)
+		}
+		t.SummarySnippet = snippet(t.SummarySnippet, summarySnippetLength)
+		topics = append(topics, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating topic stats: %w", err)
+	}
+
+	var nextCursor int64
+	if len(topics) > opts.Limit {
+		topics = topics[:opts.Limit]
+		nextCursor = int64(topics[len(topics)-1].ID)
+	}
+
+	return topics, nextCursor, nil
+}
+
+// escapeLike escapes LIKE wildcards so the value is matched literally.
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
+
+// snippet collapses whitespace and truncates text to at most max runes.
+func snippet(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
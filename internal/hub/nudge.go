@@ -0,0 +1,187 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// workKeywords mark a message as handing out work (matched case-insensitively).
+var workKeywords = []string{
+	"please", "can you", "could you", "todo", "action item", "assign",
+	"お願い", "してください", "担当",
+}
+
+// needsReply reports whether a message asks a question or assigns work,
+// so that silence after it means the topic has stalled.
+func needsReply(content string, mentioned []string) bool {
+	if len(mentioned) > 0 || strings.ContainsAny(content, "?？") {
+		return true
+	}
+	lower := strings.ToLower(content)
+	for _, keyword := range workKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// quietHours is a daily window, in minutes since local midnight, during
+// which no nudges are posted. The window may wrap past midnight.
+type quietHours struct {
+	start, end int
+}
+
+// parseQuietHours parses a "HH:MM-HH:MM" window. An empty string disables
+// quiet hours and returns nil.
+func parseQuietHours(s string) (*quietHours, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("quiet hours %q must look like 22:00-07:00", s)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours start %q", from)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours end %q", to)
+	}
+
+	return &quietHours{
+		start: start.Hour()*60 + start.Minute(),
+		end:   end.Hour()*60 + end.Minute(),
+	}, nil
+}
+
+// contains reports whether t falls inside the window. A nil window never does.
+func (q *quietHours) contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.start <= q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+// checkInactivity nudges the agents working in a topic when its latest
+// message asks a question or assigns work and nobody has replied within
+// InactivityTimeout. Each message is nudged at most once, and a topic at
+// most once per NudgeCooldown.
+func (o *Orchestrator) checkInactivity(ctx context.Context, topicID int64, now time.Time) error {
+	timeout := o.config.InactivityTimeout
+	if timeout <= 0 || o.quiet.contains(now) {
+		return nil
+	}
+
+	o.mu.Lock()
+	lastActivity := o.lastActivity[topicID]
+	o.mu.Unlock()
+	if !lastActivity.IsZero() && now.Sub(lastActivity) < timeout {
+		return nil
+	}
+
+	// The stalled message is the latest one from an agent; our own
+	// summaries and nudges do not count as replies.
+	messages, err := o.db.GetMessages(topicID, 10)
+	if err != nil {
+		return err
+	}
+	var last *db.Message
+	for i := range messages {
+		if messages[i].Sender != db.OrchestratorAgent {
+			last = &messages[i]
+			break
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	postedAt, err := time.Parse(time.RFC3339, last.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to parse message time: %w", err)
+	}
+	idle := now.Sub(postedAt)
+	if idle < timeout {
+		return nil
+	}
+
+	mentioned, err := o.db.GetMessageMentions(int64(last.ID))
+	if err != nil {
+		return err
+	}
+	if !needsReply(last.Content, mentioned) {
+		return nil
+	}
+
+	nudged, err := o.db.HasNudge(int64(last.ID))
+	if err != nil || nudged {
+		return err
+	}
+
+	if o.config.NudgeCooldown > 0 {
+		latest, err := o.db.GetLatestNudge(topicID)
+		if err != nil {
+			return err
+		}
+		if latest != nil {
+			if at, err := time.Parse(time.RFC3339, latest.CreatedAt); err == nil && now.Sub(at) < o.config.NudgeCooldown {
+				return nil
+			}
+		}
+	}
+
+	agents, err := o.db.ListAgentsInTopic(topicID)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, name := range agents {
+		if !strings.EqualFold(name, last.Sender) && !strings.EqualFold(name, db.OrchestratorAgent) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	if _, err := o.db.PostMessage(topicID, db.OrchestratorAgent, formatNudge(last, names, idle)); err != nil {
+		return err
+	}
+	if _, err := o.db.RecordNudge(topicID, int64(last.ID), names); err != nil {
+		return err
+	}
+
+	log.Printf("[Topic %d] Nudged %s about message %d (idle %d min)",
+		topicID, strings.Join(names, ", "), last.ID, int(idle.Minutes()))
+	return nil
+}
+
+// formatNudge builds the nudge post, @mentioning each agent so that their
+// wait_notify wakes up.
+func formatNudge(msg *db.Message, agents []string, idle time.Duration) string {
+	mentions := make([]string, len(agents))
+	for i, name := range agents {
+		mentions[i] = "@" + name
+	}
+
+	quote := []rune(strings.Join(strings.Fields(msg.Content), " "))
+	if len(quote) > 120 {
+		quote = append(quote[:120], '…')
+	}
+
+	return fmt.Sprintf("⏰ **Orchestrator Nudge**\n\n%s: message #%d from %s has had no reply for %d min.\n> %s",
+		strings.Join(mentions, " "), msg.ID, msg.Sender, int(idle.Minutes()), string(quote))
+}
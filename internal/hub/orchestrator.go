@@ -2,7 +2,6 @@ package hub
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -11,8 +10,6 @@ import (
 	"sync"
 	"time"
 
-	"google.golang.org/genai"
-
 	"github.com/yklcs/agent-hub-mcp/internal/config"
 	"github.com/yklcs/agent-hub-mcp/internal/db"
 )
@@ -21,10 +18,19 @@ import (
 type Config struct {
 	PollInterval     time.Duration // How often to check for new messages
 	SummaryThreshold int           // Number of messages before triggering a summary
+	SummaryChunkSize int           // Maximum messages per summarizer call when catching up
 	InactivityTimeout time.Duration // Time of no activity before nudging
+	NudgeCooldown     time.Duration // Minimum time between nudges in one topic
+	QuietHours        string        // Local "HH:MM-HH:MM" window without nudges (empty = none)
+	AuditRetentionDays int          // Delete audit events older than this (0 = keep forever)
+	EventRetention     time.Duration // Delete change feed events and sampling requests older than this (0 = keep forever)
 	// LLM Configuration
-	Model         string // Gemini model to use for summarization
-	APIKey        string // API key for Gemini (overrides env vars)
+	Sampling        bool          // Summarize through MCP sampling (agent-hub serve -sampling) before the provider
+	SamplingTimeout time.Duration // How long to wait for a sampled summary
+	Provider string // LLM provider: gemini, openai, anthropic or mock (empty = gemini)
+	Model    string // Model to use for summarization (empty = provider default)
+	BaseURL  string // API endpoint override, e.g. a local Ollama server for openai
+	APIKey   string // API key for the provider (overrides env vars)
 }
 
 // DefaultConfig returns the default orchestrator configuration.
@@ -32,8 +38,14 @@ func DefaultConfig() *Config {
 	return &Config{
 		PollInterval:     5 * time.Second,
 		SummaryThreshold: 5,
+		SummaryChunkSize: 50,
 		InactivityTimeout: 5 * time.Minute,
-		Model:            "gemini-2.0-flash-lite",
+		NudgeCooldown:     30 * time.Minute,
+		AuditRetentionDays: db.DefaultAuditRetentionDays,
+		EventRetention:     db.DefaultEventRetention,
+		Sampling:          true,
+		SamplingTimeout:   2 * time.Minute,
+		Provider:          ProviderGemini,
 	}
 }
 
@@ -41,17 +53,21 @@ func DefaultConfig() *Config {
 // 1. Config File: ~/.config/agent-hub-mcp/config.json (Field: api_key)
 // 2. Config.APIKey (explicitly set)
 // 3. HUB_MASTER_API_KEY (tool-specific)
-// 4. GEMINI_API_KEY (generic)
+// 4. The provider's own variable: GEMINI_API_KEY, OPENAI_API_KEY or ANTHROPIC_API_KEY
+// The config file and master keys aren't meant for any one endpoint, so
+// they are skipped for a BaseURL override.
 // Also returns the source name for logging.
 func (c *Config) getAPIKey() (key string, source string) {
 	// 1. Try config file
-	configPath := config.DefaultConfigPath()
-	if data, err := os.ReadFile(configPath); err == nil {
-		var config struct {
-			APIKey string `json:"api_key"`
-		}
-		if json.Unmarshal(data, &config) == nil && config.APIKey != "" {
-			return config.APIKey, "Config File (~/.config/agent-hub-mcp/config.json)"
+	if c.BaseURL == "" {
+		configPath := config.DefaultConfigPath()
+		if data, err := os.ReadFile(configPath); err == nil {
+			var config struct {
+				APIKey string `json:"api_key"`
+			}
+			if json.Unmarshal(data, &config) == nil && config.APIKey != "" {
+				return config.APIKey, "Config File (~/.config/agent-hub-mcp/config.json)"
+			}
 		}
 	}
 
@@ -61,13 +77,22 @@ func (c *Config) getAPIKey() (key string, source string) {
 	}
 
 	// 3. Try HUB_MASTER_API_KEY
-	if key := os.Getenv("HUB_MASTER_API_KEY"); key != "" {
-		return key, "HUB_MASTER_API_KEY"
+	if c.BaseURL == "" {
+		if key := os.Getenv("HUB_MASTER_API_KEY"); key != "" {
+			return key, "HUB_MASTER_API_KEY"
+		}
 	}
 
-	// 4. Try GEMINI_API_KEY
-	if key := os.Getenv("GEMINI_API_KEY"); key != "" {
-		return key, "GEMINI_API_KEY"
+	// 4. Try the provider's own variable
+	envVar := "GEMINI_API_KEY"
+	switch strings.ToLower(c.Provider) {
+	case ProviderOpenAI:
+		envVar = "OPENAI_API_KEY"
+	case ProviderAnthropic:
+		envVar = "ANTHROPIC_API_KEY"
+	}
+	if key := os.Getenv(envVar); key != "" {
+		return key, envVar
 	}
 
 	return "", "none"
@@ -75,15 +100,20 @@ func (c *Config) getAPIKey() (key string, source string) {
 
 // Orchestrator monitors the BBS and provides autonomous services.
 type Orchestrator struct {
-	db     *db.DB
-	config *Config
-	client *genai.Client
+	db         *db.DB
+	config     *Config
+	summarizers []Summarizer // Tried in order, then the mock (set by Initialize or SetSummarizer)
 
 	// Track state per topic
 	mu            sync.Mutex
 	lastSeenMsgID map[int64]int64     // topicID -> messageID
 	topicMsgCount map[int64]int        // topicID -> message count since last summary
 	lastActivity  map[int64]time.Time  // topicID -> last message time
+	lastSeenRevID map[int64]int64      // topicID -> message revision ID
+
+	quiet *quietHours // Parsed Config.QuietHours (nil = none)
+
+	lastRetention time.Time // When the retention of old events was last applied
 }
 
 // NewOrchestrator creates a new orchestrator instance.
@@ -97,40 +127,53 @@ func NewOrchestrator(database *db.DB, config *Config) *Orchestrator {
 		lastSeenMsgID:   make(map[int64]int64),
 		topicMsgCount:   make(map[int64]int),
 		lastActivity:    make(map[int64]time.Time),
+		lastSeenRevID:   make(map[int64]int64),
 	}
 }
 
-// Initialize initializes the Orchestrator, setting up the LLM client.
+// Initialize initializes the Orchestrator, setting up the summarizers in
+// fallback order: MCP sampling, then the configured LLM provider, then the
+// mock. A summarizer set with SetSummarizer is kept.
 func (o *Orchestrator) Initialize(ctx context.Context) error {
-	apiKey, source := o.config.getAPIKey()
-	if apiKey == "" {
-		log.Println("Warning: No API key found (HUB_MASTER_API_KEY or GEMINI_API_KEY), using mock summarizer")
+	if len(o.summarizers) > 0 {
 		return nil
 	}
 
-	log.Printf("Using API key from: %s", source)
+	if o.config.Sampling {
+		o.summarizers = append(o.summarizers, NewLLMSummarizer(NewSamplingProvider(o.db, o.config.SamplingTimeout)))
+		log.Println("MCP sampling enabled (used when agent-hub serve -sampling has a capable client)")
+	}
 
-	// Create Gemini client with API key
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
-	})
+	provider, err := o.config.newProvider(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create Gemini client: %w", err)
+		return err
+	}
+	if provider != nil {
+		o.summarizers = append(o.summarizers, NewLLMSummarizer(provider))
 	}
-
-	o.client = client
-	log.Printf("Gemini client initialized (model: %s)", o.config.Model)
 	return nil
 }
 
+// SetSummarizer replaces the summarizers chosen from the config, e.g. to
+// inject a fake provider in tests. It must be called before Start.
+func (o *Orchestrator) SetSummarizer(s Summarizer) {
+	o.summarizers = []Summarizer{s}
+}
+
 // Close closes the Orchestrator's resources.
 func (o *Orchestrator) Close() error {
-	// genai.Client does not require explicit closing
+	// None of the providers hold resources that need closing
 	return nil
 }
 
 // Start begins the orchestrator monitoring loop.
 func (o *Orchestrator) Start(ctx context.Context) error {
+	quiet, err := parseQuietHours(o.config.QuietHours)
+	if err != nil {
+		return err
+	}
+	o.quiet = quiet
+
 	log.Println("Orchestrator started")
 
 	// Initialize LLM client
@@ -161,85 +204,191 @@ func (o *Orchestrator) Start(ctx context.Context) error {
 	}
 }
 
-// initializeTopics sets up tracking for all existing topics.
+// initializeTopics sets up tracking for all existing topics, resuming from
+// the state persisted by a previous run where there is one.
 func (o *Orchestrator) initializeTopics() error {
 	topics, err := o.db.ListTopics()
 	if err != nil {
 		return err
 	}
 
-	o.mu.Lock()
-	defer o.mu.Unlock()
+	states, err := o.db.GetOrchestratorStates()
+	if err != nil {
+		return err
+	}
+	// Without any saved state this is the first run, so existing history is
+	// skipped. Otherwise a topic without state was created while we were
+	// stopped and all of its messages are still unsummarized.
+	resuming := len(states) > 0
+
+	latestRevID, err := o.db.LatestRevisionID()
+	if err != nil {
+		return err
+	}
 
+	var fresh []int64
+	o.mu.Lock()
 	for _, topic := range topics {
+		topicID := int64(topic.ID)
+
+		if state, ok := states[topicID]; ok {
+			o.lastSeenMsgID[topicID] = state.LastSeenMessageID
+			o.topicMsgCount[topicID] = state.UnsummarizedCount
+			o.lastSeenRevID[topicID] = state.LastSeenRevisionID
+			if !state.LastActivityAt.IsZero() {
+				o.lastActivity[topicID] = state.LastActivityAt
+			}
+			continue
+		}
+
+		o.lastSeenMsgID[topicID] = 0
+		o.topicMsgCount[topicID] = 0
+		o.lastSeenRevID[topicID] = 0
+		if resuming {
+			continue
+		}
+		o.lastSeenRevID[topicID] = latestRevID
+
 		// Get the latest message ID for this topic
-		messages, err := o.db.GetMessages(int64(topic.ID), 1)
+		messages, err := o.db.GetMessages(topicID, 1)
 		if err != nil {
 			log.Printf("Failed to get messages for topic %d: %v", topic.ID, err)
 			continue
 		}
-
 		if len(messages) > 0 {
-			o.lastSeenMsgID[int64(topic.ID)] = int64(messages[0].ID)
-		} else {
-			o.lastSeenMsgID[int64(topic.ID)] = 0
+			o.lastSeenMsgID[topicID] = int64(messages[0].ID)
+		}
+		fresh = append(fresh, topicID)
+	}
+	o.mu.Unlock()
+
+	for _, topicID := range fresh {
+		if err := o.saveState(topicID); err != nil {
+			log.Printf("Warning: %v", err)
 		}
-		o.topicMsgCount[int64(topic.ID)] = 0
 	}
 
-	log.Printf("Initialized tracking for %d topics", len(topics))
+	if resuming {
+		log.Printf("Resumed tracking for %d topics (%d with saved state)", len(topics), len(states))
+	} else {
+		log.Printf("Initialized tracking for %d topics", len(topics))
+	}
 	return nil
 }
 
+// saveState persists the in-memory tracking state of a topic, so that a
+// restarted orchestrator neither recounts nor loses unsummarized messages.
+func (o *Orchestrator) saveState(topicID int64) error {
+	o.mu.Lock()
+	state := db.OrchestratorState{
+		TopicID:            topicID,
+		LastSeenMessageID:  o.lastSeenMsgID[topicID],
+		UnsummarizedCount:  o.topicMsgCount[topicID],
+		LastSeenRevisionID: o.lastSeenRevID[topicID],
+		LastActivityAt:     o.lastActivity[topicID],
+	}
+	o.mu.Unlock()
+
+	return o.db.SaveOrchestratorState(state)
+}
+
 // pollOnce performs a single poll cycle.
 func (o *Orchestrator) pollOnce(ctx context.Context) error {
+	o.applyRetention(time.Now())
+
 	topics, err := o.db.ListTopics()
 	if err != nil {
 		return err
 	}
 
 	for _, topic := range topics {
+		// Edits are handled first, so a new summary never continues from
+		// one that is about to be refreshed
+		if err := o.checkRevisions(ctx, int64(topic.ID)); err != nil {
+			log.Printf("Error checking edits in topic %d: %v", topic.ID, err)
+		}
 		if err := o.checkTopic(ctx, int64(topic.ID)); err != nil {
 			log.Printf("Error checking topic %d: %v", topic.ID, err)
 		}
+		if err := o.checkInactivity(ctx, int64(topic.ID), time.Now()); err != nil {
+			log.Printf("Error checking inactivity of topic %d: %v", topic.ID, err)
+		}
 	}
 
 	return nil
 }
 
+// retentionInterval is how often the retention of the audit log and the
+// change feed is applied.
+const retentionInterval = time.Hour
+
+// applyRetention deletes audit events older than AuditRetentionDays and
+// change feed events and sampling requests older than EventRetention, at
+// most once per retentionInterval.
+func (o *Orchestrator) applyRetention(now time.Time) {
+	if now.Sub(o.lastRetention) < retentionInterval {
+		return
+	}
+	o.lastRetention = now
+
+	if o.config.AuditRetentionDays > 0 {
+		n, err := o.db.PruneAuditEvents(o.config.AuditRetentionDays)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else if n > 0 {
+			log.Printf("Pruned %d audit events older than %d days", n, o.config.AuditRetentionDays)
+		}
+	}
+
+	if o.config.EventRetention > 0 {
+		n, err := o.db.PruneEvents(o.config.EventRetention)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else if n > 0 {
+			log.Printf("Pruned %d change feed events older than %s", n, o.config.EventRetention)
+		}
+
+		n, err = o.db.PruneSamplingRequests(o.config.EventRetention)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else if n > 0 {
+			log.Printf("Pruned %d sampling requests older than %s", n, o.config.EventRetention)
+		}
+	}
+}
+
 // checkTopic examines a single topic for new activity.
 func (o *Orchestrator) checkTopic(ctx context.Context, topicID int64) error {
 	o.mu.Lock()
 	lastSeen := o.lastSeenMsgID[topicID]
 	o.mu.Unlock()
 
-	// Get messages newer than last seen
-	messages, err := o.db.GetMessages(topicID, 20)
+	// Get messages newer than last seen, oldest first
+	messages, err := o.db.GetMessagesSince(db.MessageQuery{
+		AfterID:  lastSeen,
+		TopicIDs: []int64{topicID},
+	})
 	if err != nil {
 		return err
 	}
 
-	// Messages come in DESC order, reverse to process chronologically
-	reverse(messages)
-
+	// The orchestrator's own summaries and nudges don't count towards the
+	// next summary
 	newMessages := 0
 	var latestMsgID int64 = lastSeen
 	var latestTime time.Time
 
 	for _, msg := range messages {
-		if int64(msg.ID) > lastSeen {
+		latestMsgID = int64(msg.ID)
+		if msg.Sender != db.OrchestratorAgent {
 			newMessages++
-			if int64(msg.ID) > latestMsgID {
-				latestMsgID = int64(msg.ID)
-			}
-			// Parse timestamp
-			if latestTime.IsZero() || msg.CreatedAt > latestTime.Format(time.RFC3339) {
-				latestTime, _ = time.Parse(time.RFC3339, msg.CreatedAt)
-			}
+		}
+		if t, err := time.Parse(time.RFC3339, msg.CreatedAt); err == nil {
+			latestTime = t
 		}
 	}
 
-	if newMessages > 0 {
+	if len(messages) > 0 {
 		o.mu.Lock()
 		o.lastSeenMsgID[topicID] = latestMsgID
 		o.topicMsgCount[topicID] += newMessages
@@ -249,6 +398,14 @@ func (o *Orchestrator) checkTopic(ctx context.Context, topicID int64) error {
 		count := o.topicMsgCount[topicID]
 		o.mu.Unlock()
 
+		if err := o.saveState(topicID); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+
+		if newMessages == 0 {
+			return nil
+		}
+
 		log.Printf("[Topic %d] %d new messages (total since last summary: %d)",
 			topicID, newMessages, count)
 
@@ -263,198 +420,270 @@ func (o *Orchestrator) checkTopic(ctx context.Context, topicID int64) error {
 	return nil
 }
 
-// generateSummary creates and posts a summary for the topic.
-func (o *Orchestrator) generateSummary(ctx context.Context, topicID int64) error {
-	log.Printf("Generating summary for topic %d...", topicID)
-
-	// Get latest summary to check if chain is broken
-	latestSummary, err := o.db.GetLatestSummary(topicID)
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("Warning: failed to get latest summary: %v", err)
-	}
+// checkRevisions refreshes the summary of a topic when messages it already
+// covers have been edited or deleted since the last check.
+func (o *Orchestrator) checkRevisions(ctx context.Context, topicID int64) error {
+	o.mu.Lock()
+	lastSeen := o.lastSeenRevID[topicID]
+	o.mu.Unlock()
 
-	// Get recent messages for summarization
-	messages, err := o.db.GetMessages(topicID, 50)
+	revisions, err := o.db.GetRevisions(db.RevisionQuery{TopicID: topicID, AfterID: lastSeen})
 	if err != nil {
 		return err
 	}
-
-	var summary string
-	isMock := false
-
-	if o.client != nil {
-		// Check if previous summary was Mock (chain broken)
-		if latestSummary != nil && latestSummary.IsMock {
-			log.Printf("Previous summary was Mock, doing full history scan")
-			summary, err = o.llmSummarizer(ctx, messages)
-		} else {
-			// Try incremental summarization
-			if latestSummary != nil {
-				summary, err = o.llmIncrementalSummarizer(ctx, latestSummary.SummaryText, messages)
-			} else {
-				summary, err = o.llmSummarizer(ctx, messages)
-			}
-		}
-
-		if err != nil {
-			log.Printf("LLM summarization failed, falling back to mock: %v", err)
-			summary = o.mockSummarizer(messages)
-			isMock = true
-		}
-	} else {
-		// Use mock summarizer
-		summary = o.mockSummarizer(messages)
-		isMock = true
+	if len(revisions) == 0 {
+		return nil
 	}
 
-	// Save summary to topic_summaries table
-	_, err = o.db.SaveSummary(topicID, summary, isMock)
+	latest, err := o.db.GetLatestSummary(topicID)
 	if err != nil {
-		log.Printf("Failed to save summary to topic_summaries: %v", err)
+		return err
 	}
 
-	// Post the summary to messages
-	_, err = o.db.PostMessage(topicID, "orchestrator", summary)
-	if err != nil {
-		return err
+	// Only the earliest changed message matters: the refresh re-summarizes
+	// everything from it onwards
+	var changedID int64
+	for _, r := range revisions {
+		if r.Sender == db.OrchestratorAgent || latest == nil || r.MessageID > latest.LastMessageID {
+			continue
+		}
+		if changedID == 0 || r.MessageID < changedID {
+			changedID = r.MessageID
+		}
 	}
 
-	// Reset counter
 	o.mu.Lock()
-	o.topicMsgCount[topicID] = 0
+	o.lastSeenRevID[topicID] = revisions[len(revisions)-1].ID
 	o.mu.Unlock()
 
-	log.Printf("Summary posted for topic %d (mock=%v)", topicID, isMock)
-	return nil
+	if changedID != 0 {
+		if err := o.refreshSummary(ctx, topicID, changedID); err != nil {
+			log.Printf("Failed to refresh summary for topic %d: %v", topicID, err)
+		}
+	}
+	return o.saveState(topicID)
 }
 
-// llmIncrementalSummarizer updates an existing summary with new messages.
-func (o *Orchestrator) llmIncrementalSummarizer(ctx context.Context, previousSummary string, messages []db.Message) (string, error) {
-	if len(messages) == 0 {
-		return previousSummary, nil
-	}
+// generateSummary summarizes the messages posted since the previous summary
+// and posts the result. The orchestrator's own posts are not summarized.
+func (o *Orchestrator) generateSummary(ctx context.Context, topicID int64) error {
+	log.Printf("Generating summary for topic %d...", topicID)
 
-	// Reverse to get chronological order
-	reverse(messages)
+	// A mock summary breaks the chain, so the summarizers continue from the
+	// latest LLM summary and cover everything after it
+	base, err := o.db.GetLatestLLMSummary(topicID)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	latest, err := o.db.GetLatestSummary(topicID)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if latest != nil && latest.IsMock {
+		var afterID int64
+		if base != nil {
+			afterID = base.LastMessageID
+		}
+		log.Printf("Previous summary was Mock, summarizing from message %d", afterID+1)
+	}
 
-	// Build incremental prompt
-	var prompt strings.Builder
-	prompt.WriteString("You are maintaining a summary of a BBS conversation.\n\n")
-	prompt.WriteString("** Previous Summary **\n")
-	prompt.WriteString(previousSummary)
-	prompt.WriteString("\n\n** New Messages **\n")
-	for i, msg := range messages {
-		prompt.WriteString(fmt.Sprintf("[%d] %s: %s\n", i+1, msg.Sender, msg.Content))
+	// The mock only reports activity since the latest summary of any kind
+	var mockAfterID int64
+	if latest != nil {
+		mockAfterID = latest.LastMessageID
 	}
-	prompt.WriteString("\n** Task **\n")
-	prompt.WriteString("Please update the summary to incorporate the new messages. ")
-	prompt.WriteString("Maintain the structure and important information from the previous summary, ")
-	prompt.WriteString("while adding new insights, decisions, or action items from the new messages.")
-	prompt.WriteString("Keep it concise and well-organized.")
+	return o.summarizeAfter(ctx, topicID, base, mockAfterID)
+}
 
-	// Generate content using new SDK pattern
-	contents := genai.Text(prompt.String())
+// refreshSummary re-summarizes a topic after message changedID, which an
+// earlier summary covers, was edited or deleted. The summarizers continue
+// from the latest up-to-date LLM summary before the message, so the changed
+// content replaces what they were told before.
+func (o *Orchestrator) refreshSummary(ctx context.Context, topicID, changedID int64) error {
+	log.Printf("Refreshing summary for topic %d after message %d changed...", topicID, changedID)
 
-	resp, err := o.client.Models.GenerateContent(ctx, o.config.Model, contents, nil)
+	base, err := o.db.GetLatestLLMSummaryBefore(topicID, changedID)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate incremental summary: %w", err)
+		log.Printf("Warning: %v", err)
 	}
+	return o.summarizeAfter(ctx, topicID, base, changedID-1)
+}
 
-	if len(resp.Candidates) == 0 {
-		return "", fmt.Errorf("no response from model")
+// summarizeAfter summarizes the messages after base (nil = from the start
+// of the topic), continuing from base's sections, and posts the result. If
+// every summarizer fails, the mock reports the messages after mockAfterID.
+func (o *Orchestrator) summarizeAfter(ctx context.Context, topicID int64, base *db.TopicSummary, mockAfterID int64) error {
+	var previous *db.SummarySections
+	var afterID int64
+	if base != nil {
+		previous = base.Sections
+		if previous == nil {
+			// Free-form summaries from before structured summaries
+			previous = &db.SummarySections{Overview: base.SummaryText}
+		}
+		afterID = base.LastMessageID
 	}
 
-	// Extract text from response
-	content := resp.Candidates[0].Content
-	if len(content.Parts) == 0 {
-		return "", fmt.Errorf("no parts in response content")
-	}
-	result := content.Parts[0].Text
-	if result == "" {
-		return "", fmt.Errorf("empty text in response")
+	messages, err := o.uncoveredMessages(topicID, afterID)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Sprintf("📊 **Orchestrator Summary (Gemini)**\n\n%s", result), nil
-}
-
-// llmSummarizer uses Gemini to create a summary of messages.
-func (o *Orchestrator) llmSummarizer(ctx context.Context, messages []db.Message) (string, error) {
-	if len(messages) == 0 {
-		return "No activity to summarize.", nil
+	var summary *db.SummarySections
+	var summarizer Summarizer
+	if len(messages) > 0 {
+		summary, summarizer = o.summarize(withTopic(ctx, topicID), previous, messages)
+	}
+	if summary == nil {
+		messages = messagesAfter(messages, mockAfterID)
+		if len(messages) > 0 {
+			if len(o.summarizers) > 0 {
+				log.Printf("All summarizers failed, falling back to mock")
+			}
+			summarizer = MockSummarizer{}
+			summary, _ = summarizer.Summarize(ctx, nil, messages)
+		}
 	}
 
-	// Reverse to get chronological order
-	reverse(messages)
+	if summary != nil {
+		_, isMock := summarizer.(MockSummarizer)
+		text := formatSummary(summarizer.Name(), summary)
+		record := db.TopicSummary{
+			TopicID:        int(topicID),
+			SummaryText:    text,
+			IsMock:         isMock,
+			FirstMessageID: int64(messages[0].ID),
+			LastMessageID:  int64(messages[len(messages)-1].ID),
+			MessageCount:   len(messages),
+			Sections:       summary,
+		}
 
-	// Build conversation history
-	var conversation strings.Builder
-	conversation.WriteString("Here is a recent conversation from a BBS topic:\n\n")
-	for i, msg := range messages {
-		conversation.WriteString(fmt.Sprintf("[%d] %s: %s\n", i+1, msg.Sender, msg.Content))
+		// Save summary to topic_summaries table
+		summaryID, err := o.db.SaveSummary(record)
+		if err != nil {
+			log.Printf("Failed to save summary to topic_summaries: %v", err)
+		} else {
+			o.draftTasks(topicID, summaryID, summary.ActionItems)
+		}
+
+		// Post the summary to messages
+		if _, err := o.db.PostMessage(topicID, db.OrchestratorAgent, text); err != nil {
+			return err
+		}
+		log.Printf("Summary posted for topic %d (messages %d-%d, mock=%v)", topicID, record.FirstMessageID, record.LastMessageID, isMock)
+	} else {
+		log.Printf("No new messages to summarize in topic %d", topicID)
 	}
-	conversation.WriteString("\nPlease provide a concise summary covering:\n")
-	conversation.WriteString("1. What was discussed?\n")
-	conversation.WriteString("2. Current status or consensus\n")
-	conversation.WriteString("3. Next steps or action items\n\n")
-	conversation.WriteString("Format the response with clear sections and bullet points.")
 
-	// Generate content using new SDK pattern
-	contents := genai.Text(conversation.String())
+	// Reset counter
+	o.mu.Lock()
+	o.topicMsgCount[topicID] = 0
+	o.mu.Unlock()
 
-	resp, err := o.client.Models.GenerateContent(ctx, o.config.Model, contents, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate summary: %w", err)
+	if err := o.saveState(topicID); err != nil {
+		log.Printf("Warning: %v", err)
 	}
 
-	if len(resp.Candidates) == 0 {
-		return "", fmt.Errorf("no response from model")
-	}
+	return nil
+}
 
-	// Extract text from response
-	content := resp.Candidates[0].Content
-	if len(content.Parts) == 0 {
-		return "", fmt.Errorf("no parts in response content")
+// draftTasks adds the action items of a summary to the task board as draft
+// tasks, skipping items the topic already has a task for.
+func (o *Orchestrator) draftTasks(topicID, summaryID int64, items []db.ActionItem) {
+	drafted := 0
+	for _, item := range items {
+		id, err := o.db.CreateDraftTask(db.Task{
+			Title:     item.Task,
+			TopicID:   int(topicID),
+			Assignee:  item.Owner,
+			CreatedBy: db.OrchestratorAgent,
+			SummaryID: int(summaryID),
+		})
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		if id != 0 {
+			drafted++
+		}
 	}
-	result := content.Parts[0].Text
-	if result == "" {
-		return "", fmt.Errorf("empty text in response")
+	if drafted > 0 {
+		log.Printf("[Topic %d] Drafted %d tasks from action items", topicID, drafted)
 	}
-
-	// Add header
-	return fmt.Sprintf("📊 **Orchestrator Summary (Gemini)**\n\n%s", result), nil
 }
 
-// mockSummarizer creates a simple summary of messages.
-// This is a fallback when LLM is not available.
-func (o *Orchestrator) mockSummarizer(messages []db.Message) string {
-	if len(messages) == 0 {
-		return "No activity to summarize."
+// uncoveredMessages returns the messages in a topic after afterID, oldest
+// first, excluding the orchestrator's own posts and deleted messages. Their
+// content is masked by the redaction rules, so secrets that got into the
+// database (e.g. posted before a rule was added) are never sent to a
+// summarizer.
+func (o *Orchestrator) uncoveredMessages(topicID, afterID int64) ([]db.Message, error) {
+	redactor, err := o.db.Redactor(db.RedactMask)
+	if err != nil {
+		return nil, err
 	}
 
-	// Reverse to get chronological order
-	reverse(messages)
-
-	// Count messages per sender
-	senderCounts := make(map[string]int)
-	for _, msg := range messages {
-		senderCounts[msg.Sender]++
+	var messages []db.Message
+	for {
+		page, err := o.db.GetMessagesSince(db.MessageQuery{
+			AfterID:       afterID,
+			TopicIDs:      []int64{topicID},
+			ExcludeSender: db.OrchestratorAgent,
+			Limit:         500,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range page {
+			if msg.Deleted {
+				continue
+			}
+			msg.Content, _ = redactor.Redact(msg.Content)
+			messages = append(messages, msg)
+		}
+		if len(page) < 500 {
+			return messages, nil
+		}
+		afterID = int64(page[len(page)-1].ID)
 	}
+}
 
-	// Build summary
-	summary := fmt.Sprintf("📊 **Orchestrator Summary (Mock)**\n\n")
-	summary += fmt.Sprintf("Activity: %d messages\n", len(messages))
-	summary += "Participants:\n"
-	for sender, count := range senderCounts {
-		summary += fmt.Sprintf("  - %s: %d messages\n", sender, count)
+// summarize runs the summarizers over messages in chunks of at most
+// SummaryChunkSize, feeding each result into the next chunk. A summarizer
+// that fails is skipped for the remaining chunks. Returns the summarizer of
+// the last chunk, or nil if every summarizer failed.
+func (o *Orchestrator) summarize(ctx context.Context, previous *db.SummarySections, messages []db.Message) (*db.SummarySections, Summarizer) {
+	chunkSize := o.config.SummaryChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(messages)
+	}
+
+	next := 0
+	summary := previous
+	for start := 0; start < len(messages); start += chunkSize {
+		chunk := messages[start:min(start+chunkSize, len(messages))]
+		for ; next < len(o.summarizers); next++ {
+			result, err := o.summarizers[next].Summarize(ctx, summary, chunk)
+			if err != nil {
+				log.Printf("%s summarization failed: %v", o.summarizers[next].Name(), err)
+				continue
+			}
+			summary = result
+			break
+		}
+		if next == len(o.summarizers) {
+			return nil, nil
+		}
 	}
-	summary += "\n[LLM integration not configured. Set HUB_MASTER_API_KEY or GEMINI_API_KEY to enable AI summarization.]"
-
-	return summary
+	return summary, o.summarizers[next]
 }
 
-// reverse reverses a slice of messages in place.
-func reverse(messages []db.Message) {
-	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
-		messages[i], messages[j] = messages[j], messages[i]
+// messagesAfter returns the messages with an ID greater than afterID.
+func messagesAfter(messages []db.Message, afterID int64) []db.Message {
+	for i, msg := range messages {
+		if int64(msg.ID) > afterID {
+			return messages[i:]
+		}
 	}
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -190,28 +191,17 @@ func TestCheckTopicWithNewMessages(t *testing.T) {
 }
 
 func TestMockSummarizer(t *testing.T) {
-	tmpDB, err := os.CreateTemp("", "test-orchestrator-*.db")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.Remove(tmpDB.Name())
-	tmpDB.Close()
-
-	database, err := db.Open(tmpDB.Name())
-	if err != nil {
-		t.Fatalf("Failed to open database: %v", err)
-	}
-	defer database.Close()
-
-	orc := NewOrchestrator(database, nil)
-
 	messages := []db.Message{
 		{ID: 1, Sender: "alice", Content: "Hello"},
 		{ID: 2, Sender: "bob", Content: "Hi"},
 		{ID: 3, Sender: "alice", Content: "How are you?"},
 	}
 
-	summary := orc.mockSummarizer(messages)
+	sections, err := MockSummarizer{}.Summarize(context.Background(), nil, messages)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	summary := formatSummary("Mock", sections)
 
 	if summary == "" {
 		t.Error("Summary should not be empty")
@@ -219,7 +209,7 @@ func TestMockSummarizer(t *testing.T) {
 
 	// Check that summary contains expected elements
 	// Note: Not checking exact format since it's a mock
-	if len(summary) < 10 {
+	if len(summary) < 10 || !strings.Contains(summary, "Activity: 3 messages") {
 		t.Errorf("Summary too short: %q", summary)
 	}
 }
@@ -295,3 +285,403 @@ func TestGenerateSummary(t *testing.T) {
 		t.Errorf("Expected message count to be reset to 0, got %d", count)
 	}
 }
+
+func TestOrchestratorResumesAfterRestart(t *testing.T) {
+	tmpDB, err := os.CreateTemp("", "test-orchestrator-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpDB.Name())
+	tmpDB.Close()
+
+	database, err := db.Open(tmpDB.Name())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	topicID, err := database.CreateTopic("Test Topic")
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	if _, err := database.PostMessage(topicID, "alice", "History before the first start"); err != nil {
+		t.Fatalf("Failed to post message: %v", err)
+	}
+
+	config := &Config{
+		PollInterval:     100 * time.Millisecond,
+		SummaryThreshold: 5,
+	}
+	post := func(database *db.DB, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if _, err := database.PostMessage(topicID, "bob", fmt.Sprintf("Message %d", i)); err != nil {
+				t.Fatalf("Failed to post message: %v", err)
+			}
+		}
+	}
+	countSummaries := func(database *db.DB) int {
+		t.Helper()
+		messages, err := database.GetMessages(topicID, 100)
+		if err != nil {
+			t.Fatalf("Failed to get messages: %v", err)
+		}
+		n := 0
+		for _, msg := range messages {
+			if msg.Sender == "orchestrator" {
+				n++
+			}
+		}
+		return n
+	}
+
+	// First run sees 3 of the 5 messages needed for a summary
+	orc := NewOrchestrator(database, config)
+	if err := orc.initializeTopics(); err != nil {
+		t.Fatalf("initializeTopics failed: %v", err)
+	}
+	post(database, 3)
+	if err := orc.checkTopic(context.Background(), topicID); err != nil {
+		t.Fatalf("checkTopic failed: %v", err)
+	}
+	if n := countSummaries(database); n != 0 {
+		t.Fatalf("Expected no summary after 3 messages, got %d", n)
+	}
+	database.Close()
+
+	// Restart on the same database file, with a message posted while stopped
+	database, err = db.Open(tmpDB.Name())
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer database.Close()
+	post(database, 1)
+
+	orc = NewOrchestrator(database, config)
+	if err := orc.initializeTopics(); err != nil {
+		t.Fatalf("initializeTopics failed: %v", err)
+	}
+	orc.mu.Lock()
+	count := orc.topicMsgCount[topicID]
+	orc.mu.Unlock()
+	if count != 3 {
+		t.Errorf("Expected resumed count 3, got %d", count)
+	}
+
+	// The message posted while stopped brings the count to 4
+	if err := orc.checkTopic(context.Background(), topicID); err != nil {
+		t.Fatalf("checkTopic failed: %v", err)
+	}
+	if n := countSummaries(database); n != 0 {
+		t.Fatalf("Expected no summary after 4 messages, got %d", n)
+	}
+
+	// The fifth message triggers the summary
+	post(database, 1)
+	if err := orc.checkTopic(context.Background(), topicID); err != nil {
+		t.Fatalf("checkTopic failed: %v", err)
+	}
+	if n := countSummaries(database); n != 1 {
+		t.Fatalf("Expected 1 summary after 5 messages, got %d", n)
+	}
+
+	states, err := database.GetOrchestratorStates()
+	if err != nil {
+		t.Fatalf("GetOrchestratorStates failed: %v", err)
+	}
+	if states[topicID].UnsummarizedCount != 0 {
+		t.Errorf("Expected persisted count reset to 0, got %d", states[topicID].UnsummarizedCount)
+	}
+	if states[topicID].LastActivityAt.IsZero() {
+		t.Error("Expected last activity to be persisted")
+	}
+}
+
+func TestInitializeTopicsCreatedWhileStopped(t *testing.T) {
+	tmpDB, err := os.CreateTemp("", "test-orchestrator-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpDB.Name())
+	tmpDB.Close()
+
+	database, err := db.Open(tmpDB.Name())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.CreateTopic("Existing Topic"); err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	if err := NewOrchestrator(database, nil).initializeTopics(); err != nil {
+		t.Fatalf("initializeTopics failed: %v", err)
+	}
+
+	// A topic created while the orchestrator was stopped
+	topicID, err := database.CreateTopic("New Topic")
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := database.PostMessage(topicID, "alice", fmt.Sprintf("Message %d", i)); err != nil {
+			t.Fatalf("Failed to post message: %v", err)
+		}
+	}
+
+	orc := NewOrchestrator(database, &Config{SummaryThreshold: 100})
+	if err := orc.initializeTopics(); err != nil {
+		t.Fatalf("initializeTopics failed: %v", err)
+	}
+	if err := orc.checkTopic(context.Background(), topicID); err != nil {
+		t.Fatalf("checkTopic failed: %v", err)
+	}
+
+	orc.mu.Lock()
+	count := orc.topicMsgCount[topicID]
+	orc.mu.Unlock()
+	if count != 2 {
+		t.Errorf("Expected both messages of the new topic counted, got %d", count)
+	}
+}
+
+func TestNeedsReply(t *testing.T) {
+	tests := []struct {
+		content   string
+		mentioned []string
+		want      bool
+	}{
+		{"Can someone review the parser?", nil, true},
+		{"レビューできますか？", nil, true},
+		{"Please update the docs", nil, true},
+		{"テストをお願いします", nil, true},
+		{"@bob the build is green", []string{"bob"}, true},
+		{"Merged, thanks", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := needsReply(tt.content, tt.mentioned); got != tt.want {
+			t.Errorf("needsReply(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 16, hour, minute, 0, 0, time.Local)
+	}
+
+	overnight, err := parseQuietHours("22:00-07:00")
+	if err != nil {
+		t.Fatalf("parseQuietHours failed: %v", err)
+	}
+	daytime, err := parseQuietHours("12:00-13:30")
+	if err != nil {
+		t.Fatalf("parseQuietHours failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		quiet *quietHours
+		t     time.Time
+		want  bool
+	}{
+		{"overnight late", overnight, at(23, 15), true},
+		{"overnight early", overnight, at(6, 59), true},
+		{"overnight end", overnight, at(7, 0), false},
+		{"overnight daytime", overnight, at(12, 0), false},
+		{"daytime inside", daytime, at(13, 0), true},
+		{"daytime outside", daytime, at(14, 0), false},
+		{"disabled", nil, at(23, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.contains(tt.t); got != tt.want {
+				t.Errorf("contains(%s) = %v, want %v", tt.t.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"22:00", "late-early", "22:00-25:00"} {
+		if _, err := parseQuietHours(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestCheckInactivity(t *testing.T) {
+	tmpDB, err := os.CreateTemp("", "test-orchestrator-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpDB.Name())
+	tmpDB.Close()
+
+	database, err := db.Open(tmpDB.Name())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	topicID, err := database.CreateTopic("Stalled Topic")
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		database.UpsertAgentPresence(name, "worker")
+		database.UpdateAgentStatus(name, "working", &topicID)
+	}
+	database.UpsertAgentPresence("dave", "worker") // Not in the topic
+
+	orc := NewOrchestrator(database, &Config{
+		SummaryThreshold:  100,
+		InactivityTimeout: 5 * time.Minute,
+		NudgeCooldown:     30 * time.Minute,
+	})
+	ctx := context.Background()
+	start := time.Now()
+
+	nudges := func() []db.Message {
+		t.Helper()
+		messages, err := database.GetMessages(topicID, 100)
+		if err != nil {
+			t.Fatalf("Failed to get messages: %v", err)
+		}
+		var result []db.Message
+		for _, msg := range messages {
+			if msg.Sender == "orchestrator" {
+				result = append(result, msg)
+			}
+		}
+		return result
+	}
+	check := func(after time.Duration) {
+		t.Helper()
+		if err := orc.checkInactivity(ctx, topicID, start.Add(after)); err != nil {
+			t.Fatalf("checkInactivity failed: %v", err)
+		}
+	}
+
+	question, _ := database.PostMessage(topicID, "alice", "Can someone review the parser?")
+
+	check(2 * time.Minute)
+	if n := len(nudges()); n != 0 {
+		t.Fatalf("Expected no nudge before the timeout, got %d", n)
+	}
+
+	check(6 * time.Minute)
+	posted := nudges()
+	if len(posted) != 1 {
+		t.Fatalf("Expected 1 nudge after the timeout, got %d", len(posted))
+	}
+	if !strings.Contains(posted[0].Content, "@bob @carol:") || strings.Contains(posted[0].Content, "@alice") || strings.Contains(posted[0].Content, "@dave") {
+		t.Errorf("Expected nudge to name bob and carol only, got %q", posted[0].Content)
+	}
+	if mentioned, _ := database.GetMessageMentions(int64(posted[0].ID)); len(mentioned) != 2 {
+		t.Errorf("Expected nudge to mention 2 agents, got %v", mentioned)
+	}
+	if nudged, _ := database.HasNudge(question); !nudged {
+		t.Error("Expected the nudge to be recorded")
+	}
+
+	// The same message is never nudged twice
+	check(60 * time.Minute)
+	if n := len(nudges()); n != 1 {
+		t.Errorf("Expected the stalled message to be nudged once, got %d", n)
+	}
+
+	// Statements do not stall a topic
+	database.PostMessage(topicID, "bob", "Reviewed, looks fine")
+	check(60 * time.Minute)
+	if n := len(nudges()); n != 1 {
+		t.Errorf("Expected no nudge after a plain reply, got %d", n)
+	}
+
+	// A new question waits out the per-topic cooldown
+	database.PostMessage(topicID, "carol", "@bob can you merge it?")
+	check(20 * time.Minute)
+	if n := len(nudges()); n != 1 {
+		t.Errorf("Expected no nudge during the cooldown, got %d", n)
+	}
+
+	orc.quiet = &quietHours{start: 0, end: 24 * 60}
+	check(40 * time.Minute)
+	if n := len(nudges()); n != 1 {
+		t.Errorf("Expected no nudge during quiet hours, got %d", n)
+	}
+
+	orc.quiet = nil
+	check(40 * time.Minute)
+	posted = nudges()
+	if len(posted) != 2 {
+		t.Fatalf("Expected a second nudge after the cooldown, got %d", len(posted))
+	}
+	if !strings.Contains(posted[0].Content, "@alice @bob:") {
+		t.Errorf("Expected nudge to name alice and bob, got %q", posted[0].Content)
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	database, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	insertOld := func() {
+		database.Exec("INSERT INTO audit_events (actor, source, action, args_hash, result, created_at) VALUES ('old', 'cli', 'setup', '', 'ok', datetime('now', '-100 days'))")
+	}
+	count := func() int {
+		events, _ := database.ListAuditEvents(db.AuditQuery{})
+		return len(events)
+	}
+
+	insertOld()
+	database.RecordAudit(db.NewAuditEvent("alice", "", db.AuditSourceMCP, "bbs_post", nil, nil))
+
+	// Change feed events are pruned in the same sweep
+	topicID, _ := database.CreateTopic("Events")
+	database.PostMessage(topicID, "alice", "old")
+	database.Exec("UPDATE hub_events SET created_at = datetime('now', '-2 days')")
+	database.PostMessage(topicID, "alice", "new")
+
+	// So are sampling requests
+	oldRequest, _ := database.CreateSamplingRequest(topicID, "old prompt", "", 256)
+	database.Exec("UPDATE sampling_requests SET created_at = datetime('now', '-2 days')")
+	newRequest, _ := database.CreateSamplingRequest(topicID, "new prompt", "", 256)
+
+	orc := NewOrchestrator(database, nil)
+	now := time.Now()
+	orc.applyRetention(now)
+	if n := count(); n != 1 {
+		t.Fatalf("expected the old event to be pruned, %d events left", n)
+	}
+	if events, _ := database.GetEventsSince(0, 10); len(events) != 1 || events[0].Content != "new" {
+		t.Errorf("expected only the recent change feed event to be kept, got %+v", events)
+	}
+	if _, err := database.GetSamplingRequest(oldRequest); err == nil {
+		t.Error("expected the old sampling request to be pruned")
+	}
+	if _, err := database.GetSamplingRequest(newRequest); err != nil {
+		t.Errorf("expected the recent sampling request to be kept: %v", err)
+	}
+
+	// Retention is applied at most once per interval
+	insertOld()
+	orc.applyRetention(now.Add(time.Minute))
+	if n := count(); n != 2 {
+		t.Errorf("expected no prune within the interval, %d events left", n)
+	}
+	orc.applyRetention(now.Add(retentionInterval))
+	if n := count(); n != 1 {
+		t.Errorf("expected a prune after the interval, %d events left", n)
+	}
+
+	// A retention of 0 keeps everything
+	insertOld()
+	orc.config.AuditRetentionDays = 0
+	orc.applyRetention(now.Add(2 * retentionInterval))
+	if n := count(); n != 2 {
+		t.Errorf("expected events to be kept, %d events left", n)
+	}
+}
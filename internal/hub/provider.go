@@ -0,0 +1,419 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// Supported values for Config.Provider.
+const (
+	ProviderGemini    = "gemini"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderMock      = "mock"
+)
+
+// Default models used when Config.Model is empty.
+const (
+	defaultGeminiModel    = "gemini-2.0-flash-lite"
+	defaultOpenAIModel    = "gpt-4o-mini"
+	defaultAnthropicModel = "claude-3-5-haiku-latest"
+)
+
+// Default endpoints used when Config.BaseURL is empty.
+const (
+	defaultOpenAIBaseURL    = "https://api.openai.com/v1"
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	anthropicVersion        = "2023-06-01"
+)
+
+// Summarizer turns topic messages into a structured summary.
+type Summarizer interface {
+	// Name identifies the summarizer in summary headers and logs.
+	Name() string
+	// Summarize summarizes messages, oldest first. previous is the summary
+	// to extend, or nil to summarize the messages from scratch.
+	Summarize(ctx context.Context, previous *db.SummarySections, messages []db.Message) (*db.SummarySections, error)
+}
+
+// LLMProvider generates a text completion for a single prompt.
+type LLMProvider interface {
+	// Name identifies the provider in summary headers and logs.
+	Name() string
+	Generate(ctx context.Context, prompt string) (string, error)
+}
+
+// MockSummarizer counts messages per sender. It is the fallback when no LLM
+// provider is configured or the provider fails.
+type MockSummarizer struct{}
+
+// Name implements Summarizer.
+func (MockSummarizer) Name() string { return "Mock" }
+
+// Summarize implements Summarizer. The previous summary is ignored.
+func (MockSummarizer) Summarize(ctx context.Context, previous *db.SummarySections, messages []db.Message) (*db.SummarySections, error) {
+	if len(messages) == 0 {
+		return &db.SummarySections{Overview: "No activity to summarize."}, nil
+	}
+
+	// Count messages per sender
+	senderCounts := make(map[string]int)
+	for _, msg := range messages {
+		senderCounts[msg.Sender]++
+	}
+
+	// Build summary
+	overview := fmt.Sprintf("Activity: %d messages\n", len(messages))
+	overview += "Participants:\n"
+	for sender, count := range senderCounts {
+		overview += fmt.Sprintf("  - %s: %d messages\n", sender, count)
+	}
+	overview += "\n[LLM integration not configured. Set HUB_MASTER_API_KEY or GEMINI_API_KEY (or configure another provider) to enable AI summarization.]"
+
+	return &db.SummarySections{Overview: overview}, nil
+}
+
+// LLMSummarizer builds summarization prompts and sends them to an LLMProvider.
+type LLMSummarizer struct {
+	provider LLMProvider
+}
+
+// NewLLMSummarizer creates a summarizer backed by provider.
+func NewLLMSummarizer(provider LLMProvider) *LLMSummarizer {
+	return &LLMSummarizer{provider: provider}
+}
+
+// Name implements Summarizer.
+func (s *LLMSummarizer) Name() string { return s.provider.Name() }
+
+// Summarize implements Summarizer. With a previous summary the model is asked
+// to update it; otherwise it summarizes the messages from scratch. Either
+// way the model is asked to answer in the summaryJSON schema.
+func (s *LLMSummarizer) Summarize(ctx context.Context, previous *db.SummarySections, messages []db.Message) (*db.SummarySections, error) {
+	var prompt strings.Builder
+
+	if previous != nil {
+		if len(messages) == 0 {
+			return previous, nil
+		}
+
+		prompt.WriteString("You are maintaining a summary of a BBS conversation.\n\n")
+		prompt.WriteString("** Previous Summary **\n")
+		prompt.WriteString(encodeSummary(previous))
+		prompt.WriteString("\n\n** New Messages **\n")
+		for i, msg := range messages {
+			prompt.WriteString(fmt.Sprintf("[%d] %s: %s\n", i+1, msg.Sender, msg.Content))
+		}
+		prompt.WriteString("\n** Task **\n")
+		prompt.WriteString("Please update the summary to incorporate the new messages. ")
+		prompt.WriteString("Keep the decisions and action items from the previous summary unless the new messages change them, ")
+		prompt.WriteString("drop open questions that have been answered and risks that have been resolved, ")
+		prompt.WriteString("and add new decisions, action items, questions and risks. ")
+		prompt.WriteString("Keep it concise.\n\n")
+	} else {
+		if len(messages) == 0 {
+			return &db.SummarySections{Overview: "No activity to summarize."}, nil
+		}
+
+		prompt.WriteString("Here is a recent conversation from a BBS topic:\n\n")
+		for i, msg := range messages {
+			prompt.WriteString(fmt.Sprintf("[%d] %s: %s\n", i+1, msg.Sender, msg.Content))
+		}
+		prompt.WriteString("\nPlease provide a concise summary: what was discussed and the current status or consensus, ")
+		prompt.WriteString("the decisions made, the action items with the agent who owns each, ")
+		prompt.WriteString("the questions still open and the risks raised.\n\n")
+	}
+	prompt.WriteString(summaryFormatInstructions)
+
+	result, err := s.provider.Generate(ctx, prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	return parseSummary(result), nil
+}
+
+// newProvider creates the LLM provider selected by the config. It returns
+// nil (use the mock summarizer) when the provider is "mock" or has no
+// credentials.
+func (c *Config) newProvider(ctx context.Context) (LLMProvider, error) {
+	name := strings.ToLower(c.Provider)
+	if name == "" {
+		name = ProviderGemini
+	}
+	if name == ProviderMock {
+		log.Println("Using mock summarizer (provider: mock)")
+		return nil, nil
+	}
+
+	apiKey, source := c.getAPIKey()
+
+	switch name {
+	case ProviderGemini:
+		if apiKey == "" {
+			log.Println("Warning: No API key found (HUB_MASTER_API_KEY or GEMINI_API_KEY), using mock summarizer")
+			return nil, nil
+		}
+		log.Printf("Using API key from: %s", source)
+		model := c.modelOr(defaultGeminiModel)
+		provider, err := NewGeminiProvider(ctx, apiKey, model, c.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Gemini client initialized (model: %s)", model)
+		return provider, nil
+
+	case ProviderOpenAI:
+		// Local OpenAI-compatible servers (Ollama, llama.cpp) need no key.
+		if apiKey == "" && c.BaseURL == "" {
+			log.Println("Warning: No API key found (HUB_MASTER_API_KEY or OPENAI_API_KEY) and no base URL set, using mock summarizer")
+			return nil, nil
+		}
+		if apiKey != "" {
+			log.Printf("Using API key from: %s", source)
+		}
+		provider := &OpenAIProvider{BaseURL: c.BaseURL, APIKey: apiKey, Model: c.modelOr(defaultOpenAIModel)}
+		log.Printf("OpenAI-compatible provider initialized (model: %s, base URL: %s)", provider.Model, c.BaseURL)
+		return provider, nil
+
+	case ProviderAnthropic:
+		if apiKey == "" {
+			log.Println("Warning: No API key found (HUB_MASTER_API_KEY or ANTHROPIC_API_KEY), using mock summarizer")
+			return nil, nil
+		}
+		log.Printf("Using API key from: %s", source)
+		provider := &AnthropicProvider{BaseURL: c.BaseURL, APIKey: apiKey, Model: c.modelOr(defaultAnthropicModel)}
+		log.Printf("Anthropic provider initialized (model: %s)", provider.Model)
+		return provider, nil
+
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (want %s, %s, %s or %s)",
+			c.Provider, ProviderGemini, ProviderOpenAI, ProviderAnthropic, ProviderMock)
+	}
+}
+
+// modelOr returns the configured model, or def if none is set.
+func (c *Config) modelOr(def string) string {
+	if c.Model != "" {
+		return c.Model
+	}
+	return def
+}
+
+// GeminiProvider generates text with the Google Gemini API.
+type GeminiProvider struct {
+	client *genai.Client
+	model  string
+}
+
+// NewGeminiProvider creates a Gemini provider. baseURL overrides the API
+// endpoint and may be empty.
+func NewGeminiProvider(ctx context.Context, apiKey, model, baseURL string) (*GeminiProvider, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:      apiKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: baseURL},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	return &GeminiProvider{client: client, model: model}, nil
+}
+
+// Name implements LLMProvider.
+func (p *GeminiProvider) Name() string { return "Gemini" }
+
+// Generate implements LLMProvider.
+func (p *GeminiProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := p.client.Models.GenerateContent(ctx, p.model, genai.Text(prompt), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return "", fmt.Errorf("no response from model")
+	}
+
+	// Extract text from response
+	content := resp.Candidates[0].Content
+	if len(content.Parts) == 0 {
+		return "", fmt.Errorf("no parts in response content")
+	}
+	result := content.Parts[0].Text
+	if result == "" {
+		return "", fmt.Errorf("empty text in response")
+	}
+
+	return result, nil
+}
+
+// OpenAIProvider generates text with an OpenAI-compatible Chat Completions
+// API, such as OpenAI itself or a local Ollama or llama.cpp server.
+type OpenAIProvider struct {
+	BaseURL    string       // API root including the version, e.g. http://localhost:11434/v1 (empty = OpenAI)
+	APIKey     string       // Sent as a bearer token when set
+	Model      string       // Model name
+	HTTPClient *http.Client // Client to use (nil = default with timeout)
+}
+
+// Name implements LLMProvider.
+func (p *OpenAIProvider) Name() string { return "OpenAI" }
+
+// Generate implements LLMProvider.
+func (p *OpenAIProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+
+	request := map[string]interface{}{
+		"model": p.Model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+	}
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, p.HTTPClient, strings.TrimSuffix(baseURL, "/")+"/chat/completions", headers, request, &response); err != nil {
+		return "", err
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response from model")
+	}
+	if response.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("empty text in response")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+// AnthropicProvider generates text with the Anthropic Messages API.
+type AnthropicProvider struct {
+	BaseURL    string       // API root without the version (empty = Anthropic)
+	APIKey     string       // Sent as x-api-key
+	Model      string       // Model name
+	MaxTokens  int          // Maximum tokens to generate (0 = 1024)
+	HTTPClient *http.Client // Client to use (nil = default with timeout)
+}
+
+// Name implements LLMProvider.
+func (p *AnthropicProvider) Name() string { return "Anthropic" }
+
+// Generate implements LLMProvider.
+func (p *AnthropicProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	maxTokens := p.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+
+	request := map[string]interface{}{
+		"model":      p.Model,
+		"max_tokens": maxTokens,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+	}
+	headers := map[string]string{
+		"x-api-key":         p.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := postJSON(ctx, p.HTTPClient, strings.TrimSuffix(baseURL, "/")+"/v1/messages", headers, request, &response); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("empty text in response")
+	}
+
+	return text.String(), nil
+}
+
+// postJSON sends request as JSON to url and decodes the JSON response into
+// response. Non-2xx replies are returned as errors including the API's own
+// error message when there is one.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, request, response interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Minute}
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("API returned %s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("API returned %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// samplingSystemPrompt is sent with every sampling request.
+const samplingSystemPrompt = "You summarize discussions between AI agents on a shared bulletin board. Answer in exactly the format requested."
+
+// SamplingProvider generates text through MCP sampling: the prompt is
+// queued in the database and answered by an `agent-hub serve -sampling`
+// process, which asks one of its connected clients' models.
+type SamplingProvider struct {
+	db           *db.DB
+	ClaimTimeout time.Duration // How long to wait for a serve process to pick the request up
+	Timeout      time.Duration // How long to wait for the result in total
+	PollInterval time.Duration // How often to check for the result
+	MaxTokens    int           // Maximum tokens to generate
+}
+
+// NewSamplingProvider creates a sampling provider that waits up to timeout
+// for a result.
+func NewSamplingProvider(database *db.DB, timeout time.Duration) *SamplingProvider {
+	return &SamplingProvider{
+		db:           database,
+		ClaimTimeout: 10 * time.Second,
+		Timeout:      timeout,
+		PollInterval: 500 * time.Millisecond,
+		MaxTokens:    1024,
+	}
+}
+
+// topicKey is the context key for the topic a summarizer is working on.
+type topicKey struct{}
+
+// withTopic returns a context for summarizing topicID. Sampling requests
+// carry the topic, so they are only sent to clients that may read it.
+func withTopic(ctx context.Context, topicID int64) context.Context {
+	return context.WithValue(ctx, topicKey{}, topicID)
+}
+
+// topicFromContext returns the topic set by withTopic, or 0.
+func topicFromContext(ctx context.Context) int64 {
+	topicID, _ := ctx.Value(topicKey{}).(int64)
+	return topicID
+}
+
+// Name implements LLMProvider.
+func (p *SamplingProvider) Name() string { return "MCP Sampling" }
+
+// Generate implements LLMProvider. It fails immediately when no serve
+// process currently offers a sampling-capable client.
+func (p *SamplingProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	clients, err := p.db.CountSamplingClients(db.SamplingWorkerTTL)
+	if err != nil {
+		return "", err
+	}
+	if clients == 0 {
+		return "", fmt.Errorf("no sampling-capable MCP client is connected to agent-hub serve -sampling")
+	}
+
+	id, err := p.db.CreateSamplingRequest(topicFromContext(ctx), prompt, samplingSystemPrompt, p.MaxTokens)
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(p.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := p.db.FailSamplingRequest(id, "cancelled by orchestrator"); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+
+		req, err := p.db.GetSamplingRequest(id)
+		if err != nil {
+			return "", err
+		}
+
+		switch req.Status {
+		case db.SamplingDone:
+			if err := p.db.ClearSamplingResult(id); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			return req.Result, nil
+		case db.SamplingFailed:
+			return "", fmt.Errorf("sampling failed: %s", req.Error)
+		case db.SamplingPending:
+			if time.Since(start) >= p.ClaimTimeout {
+				cancelled, err := p.db.CancelPendingSamplingRequest(id, "not claimed in time")
+				if err != nil {
+					return "", err
+				}
+				if cancelled {
+					return "", fmt.Errorf("no serve process claimed the sampling request within %s", p.ClaimTimeout)
+				}
+			}
+		}
+
+		if time.Since(start) >= p.Timeout {
+			if _, err := p.db.FailSamplingRequest(id, "timed out"); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			return "", fmt.Errorf("sampling timed out after %s", p.Timeout)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// summaryJSON is the schema models are asked to answer in.
+type summaryJSON struct {
+	Overview      string           `json:"overview"`
+	Decisions     []string         `json:"decisions"`
+	ActionItems   []actionItemJSON `json:"action_items"`
+	OpenQuestions []string         `json:"open_questions"`
+	Risks         []string         `json:"risks"`
+}
+
+type actionItemJSON struct {
+	Task  string `json:"task"`
+	Owner string `json:"owner"`
+}
+
+// summaryFormatInstructions ends every summarization prompt.
+const summaryFormatInstructions = `Respond with a single JSON object and nothing else, in this format:
+{
+  "overview": "A few sentences on what was discussed and the current status",
+  "decisions": ["Decision that was agreed on"],
+  "action_items": [{"task": "Work to be done", "owner": "Agent responsible, or empty if unassigned"}],
+  "open_questions": ["Question that is still unanswered"],
+  "risks": ["Risk or concern that was raised"]
+}
+Use empty lists for sections with nothing to report.`
+
+// encodeSummary renders sections in the summaryJSON schema for a prompt.
+func encodeSummary(sections *db.SummarySections) string {
+	s := summaryJSON{
+		Overview:      sections.Overview,
+		Decisions:     db.EmptyIfNil(sections.Decisions),
+		ActionItems:   []actionItemJSON{},
+		OpenQuestions: db.EmptyIfNil(sections.OpenQuestions),
+		Risks:         db.EmptyIfNil(sections.Risks),
+	}
+	for _, item := range sections.ActionItems {
+		s.ActionItems = append(s.ActionItems, actionItemJSON{Task: item.Task, Owner: item.Owner})
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return sections.Overview
+	}
+	return string(data)
+}
+
+// parseSummary extracts the summaryJSON object from a model's answer,
+// tolerating surrounding text and code fences. An answer without valid JSON
+// is kept as a free-form overview.
+func parseSummary(text string) *db.SummarySections {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+
+	var s summaryJSON
+	if start < 0 || end < start || json.Unmarshal([]byte(text[start:end+1]), &s) != nil {
+		return &db.SummarySections{Overview: strings.TrimSpace(text)}
+	}
+
+	sections := &db.SummarySections{
+		Overview:      strings.TrimSpace(s.Overview),
+		Decisions:     nonEmpty(s.Decisions),
+		OpenQuestions: nonEmpty(s.OpenQuestions),
+		Risks:         nonEmpty(s.Risks),
+	}
+	for _, item := range s.ActionItems {
+		if task := strings.TrimSpace(item.Task); task != "" {
+			owner := strings.TrimPrefix(strings.TrimSpace(item.Owner), "@")
+			sections.ActionItems = append(sections.ActionItems, db.ActionItem{Task: task, Owner: owner})
+		}
+	}
+	return sections
+}
+
+// formatSummary renders sections as the markdown post for a topic. Owners
+// are not @mentioned, so repeating an action item doesn't notify them again.
+func formatSummary(name string, sections *db.SummarySections) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📊 **Orchestrator Summary (%s)**\n\n%s", name, sections.Overview)
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n\n**%s**", title)
+		for _, item := range items {
+			sb.WriteString("\n- " + item)
+		}
+	}
+
+	writeList("Decisions", sections.Decisions)
+	var actions []string
+	for _, item := range sections.ActionItems {
+		if item.Owner != "" {
+			actions = append(actions, fmt.Sprintf("%s (owner: %s)", item.Task, item.Owner))
+		} else {
+			actions = append(actions, item.Task)
+		}
+	}
+	writeList("Action Items", actions)
+	writeList("Open Questions", sections.OpenQuestions)
+	writeList("Risks", sections.Risks)
+
+	return sb.String()
+}
+
+// nonEmpty returns the trimmed, non-blank entries of items.
+func nonEmpty(items []string) []string {
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// registerACLTools registers the topic access control tools.
+func (s *Server) registerACLTools() {
+	entryDescription := "Agent names, or role names prefixed with \"" + db.RolePrefix + "\""
+
+	// topic_set_acl tool
+	setACLTool := mcp.NewTool(
+		"topic_set_acl",
+		mcp.WithDescription("Restrict who may read, post to and manage a topic. Owners can do everything, writers can read and post, readers can only read. Replaces the whole ACL; empty lists open the topic to every agent. Only owners may change an ACL, only admins may restrict an open topic, and you are always kept as an owner."),
+		mcp.WithNumber("topic_id",
+			mcp.Required(),
+			mcp.Description("The ID of the topic"),
+		),
+		mcp.WithArray("owners",
+			mcp.Description(entryDescription+" that may manage the ACL"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray("writers",
+			mcp.Description(entryDescription+" that may read and post"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray("readers",
+			mcp.Description(entryDescription+" that may only read"),
+			mcp.WithStringItems(),
+		),
+	)
+
+	s.mcpServer.AddTool(setACLTool, s.handleTopicSetACL)
+
+	// topic_get_acl tool
+	getACLTool := mcp.NewTool(
+		"topic_get_acl",
+		mcp.WithDescription("Show who may read, post to and manage a topic, and your own access"),
+		mcp.WithNumber("topic_id",
+			mcp.Required(),
+			mcp.Description("The ID of the topic"),
+		),
+	)
+
+	s.mcpServer.AddTool(getACLTool, s.handleTopicGetACL)
+}
+
+// getPrincipal returns the agent and role topic access is checked for.
+// The caller is an admin if its API token has db.RoleAdmin or the operator
+// listed it in AdminAgents; a role registered by the session never counts.
+func (s *Server) getPrincipal(ctx context.Context) db.Principal {
+	p := db.Principal{Agent: s.getSender(ctx), Role: s.getRole(ctx)}
+	if token := tokenFromContext(ctx); token != nil && token.Role == db.RoleAdmin {
+		p.Admin = true
+	}
+	for _, agent := range s.AdminAgents {
+		if agent == p.Agent {
+			p.Admin = true
+		}
+	}
+	return p
+}
+
+// checkTopicAccess returns an error result, and records the denial, if the
+// caller lacks access to a topic for operation. It returns nil if the
+// operation may go ahead.
+func (s *Server) checkTopicAccess(ctx context.Context, topicID int64, access, operation string) *mcp.CallToolResult {
+	p := s.getPrincipal(ctx)
+	allowed, err := s.db.CheckTopicAccess(topicID, p, access)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check topic access: %v", err))
+	}
+	if allowed {
+		return nil
+	}
+
+	if err := s.db.RecordACLDenial(topicID, p, operation); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("access denied: %s (role %s) is not a %s of topic %d", p.Agent, p.Role, access, topicID))
+}
+
+// handleTopicSetACL handles the topic_set_acl tool.
+func (s *Server) handleTopicSetACL(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	topicID, err := req.RequireFloat("topic_id")
+	if err != nil {
+		return mcp.NewToolResultError("topic_id is required and must be a number"), nil
+	}
+
+	if denied := s.checkTopicAccess(ctx, int64(topicID), db.AccessOwner, "set_acl"); denied != nil {
+		return denied, nil
+	}
+
+	// Everyone passes the owner check of an open topic, so only admins may
+	// restrict one; otherwise any agent could take a topic over
+	p := s.getPrincipal(ctx)
+	current, err := s.db.GetTopicACL(int64(topicID))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get topic ACL: %v", err)), nil
+	}
+	if current.IsOpen() && !p.Admin {
+		if err := s.db.RecordACLDenial(int64(topicID), p, "set_acl"); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("access denied: topic %d is open, and only admins may restrict it (or use 'agent-hub acl set')", int64(topicID))), nil
+	}
+
+	acl := db.TopicACL{
+		TopicID: int64(topicID),
+		Owners:  req.GetStringSlice("owners", nil),
+		Writers: req.GetStringSlice("writers", nil),
+		Readers: req.GetStringSlice("readers", nil),
+	}
+
+	// Don't let the caller lock themselves out of the topic
+	if !acl.IsOpen() && !acl.Grants(p, db.AccessOwner) {
+		acl.Owners = append(acl.Owners, p.Agent)
+	}
+
+	if err := s.db.SetTopicACL(acl); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to set topic ACL: %v", err)), nil
+	}
+
+	return s.aclResult(ctx, int64(topicID))
+}
+
+// handleTopicGetACL handles the topic_get_acl tool.
+func (s *Server) handleTopicGetACL(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	topicID, err := req.RequireFloat("topic_id")
+	if err != nil {
+		return mcp.NewToolResultError("topic_id is required and must be a number"), nil
+	}
+
+	if denied := s.checkTopicAccess(ctx, int64(topicID), db.AccessReader, "get_acl"); denied != nil {
+		return denied, nil
+	}
+
+	return s.aclResult(ctx, int64(topicID))
+}
+
+// aclResult returns a topic's ACL and the caller's access to it as JSON.
+func (s *Server) aclResult(ctx context.Context, topicID int64) (*mcp.CallToolResult, error) {
+	acl, err := s.db.GetTopicACL(topicID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get topic ACL: %v", err)), nil
+	}
+
+	p := s.getPrincipal(ctx)
+	yourAccess := ""
+	for _, access := range []string{db.AccessOwner, db.AccessWriter, db.AccessReader} {
+		if acl.Grants(p, access) {
+			yourAccess = access
+			break
+		}
+	}
+
+	response := map[string]interface{}{
+		"topic_id":    acl.TopicID,
+		"open":        acl.IsOpen(),
+		"owners":      db.EmptyIfNil(acl.Owners),
+		"writers":     db.EmptyIfNil(acl.Writers),
+		"readers":     db.EmptyIfNil(acl.Readers),
+		"your_access": yourAccess,
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal topic ACL: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// readOnlyTools are the tools left out of the audit log. Every other tool,
+// including ones added later, is audited.
+var readOnlyTools = map[string]bool{
+	"bbs_read":         true,
+	"bbs_read_thread":  true,
+	"bbs_list_topics":  true,
+	"bbs_search":       true,
+	"bbs_get_summary":  true,
+	"bbs_read_dms":     true,
+	"check_hub_status": true,
+	"wait_notify":      true,
+	"topic_get_acl":    true,
+	"kv_get":           true,
+	"kv_list":          true,
+	"kv_history":       true,
+	"lock_list":        true,
+	"task_list":        true,
+}
+
+// auditToolCalls is tool handler middleware recording every mutating tool
+// call in the audit log. The actor is the caller's identity before the
+// call, so bbs_register_agent is attributed to whoever registered.
+func (s *Server) auditToolCalls(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if readOnlyTools[req.Params.Name] {
+			return next(ctx, req)
+		}
+
+		actor := s.getSender(ctx)
+		result, err := next(ctx, req)
+
+		failure := err
+		if failure == nil && result != nil && result.IsError {
+			failure = errors.New(resultText(result))
+		}
+		event := db.NewAuditEvent(actor, sessionKey(ctx), db.AuditSourceMCP, req.Params.Name, req.GetArguments(), failure)
+		if recordErr := s.db.RecordAudit(event); recordErr != nil {
+			log.Printf("Warning: %v", recordErr)
+		}
+		return result, err
+	}
+}
+
+// resultText returns the text of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			return text.Text
+		}
+	}
+	return "tool error"
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// tokenKey is the context key for the API token a request authenticated
+// with.
+type tokenKey struct{}
+
+// tokenFromContext returns the API token of the request, or nil for
+// requests that didn't need one (stdio, or HTTP without EnableAuth).
+func tokenFromContext(ctx context.Context) *db.APIToken {
+	token, _ := ctx.Value(tokenKey{}).(*db.APIToken)
+	return token
+}
+
+// httpKey is the context key marking requests that came in over an HTTP
+// transport.
+type httpKey struct{}
+
+// fromHTTP reports whether the request came in over an HTTP transport
+// rather than stdio.
+func fromHTTP(ctx context.Context) bool {
+	return ctx.Value(httpKey{}) != nil
+}
+
+// EnableAuth requires every HTTP request to carry an API token
+// ("Authorization: Bearer <token>"). Requests act as the agent the token is
+// bound to, whatever name they register with.
+func (s *Server) EnableAuth() {
+	s.authRequired = true
+}
+
+// httpMiddleware applies the CORS policy and, if enabled, token
+// authentication to a transport handler.
+func (s *Server) httpMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := s.originAllowed(origin)
+		if allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, MCP-Protocol-Version, Mcp-Session-Id")
+			w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+			if allowed != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+		}
+
+		// Browsers send Origin on cross-origin requests; refuse the ones
+		// not on the allowlist so web pages can't drive the hub
+		if origin != "" && allowed == "" {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		// Handle Preflight (OPTIONS) requests
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), httpKey{}, true))
+
+		if s.authRequired {
+			token, err := s.authenticate(r)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+				http.Error(w, "authentication failed", http.StatusInternalServerError)
+				return
+			}
+			if token == nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="agent-hub"`)
+				http.Error(w, "missing or invalid API token", http.StatusUnauthorized)
+				return
+			}
+
+			// Don't let one token's holder drive another's session
+			sessionID := r.Header.Get(server.HeaderKeySessionID)
+			if sessionID == "" {
+				sessionID = r.URL.Query().Get("sessionId")
+			}
+			if sessionID != "" && !s.sessionUsableBy(sessionID, token) {
+				http.Error(w, "session belongs to another token", http.StatusForbidden)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), tokenKey{}, token))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed returns the Access-Control-Allow-Origin value for origin,
+// or "" if it may not make cross-origin requests.
+func (s *Server) originAllowed(origin string) string {
+	for _, allowed := range s.AllowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// authenticate returns the active API token of a request, or nil if it has
+// none.
+func (s *Server) authenticate(r *http.Request) (*db.APIToken, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+	return s.db.AuthenticateToken(strings.TrimSpace(token))
+}
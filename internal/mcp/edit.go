@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// registerEditTools registers the message edit and delete tools.
+func (s *Server) registerEditTools() {
+	// bbs_edit tool
+	editTool := mcp.NewTool(
+		"bbs_edit",
+		mcp.WithDescription("Correct a message you posted. The previous content is kept in the message's revision history, and the message is marked as edited. Admins (tokens with the \""+db.RoleAdmin+"\" role, or agents listed in serve -admins) may edit anyone's messages."),
+		mcp.WithNumber("message_id",
+			mcp.Required(),
+			mcp.Description("The ID of the message to edit"),
+		),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("The new message content"),
+		),
+		mcp.WithString("signature",
+			mcp.Description("Base64 ed25519 signature of the edited message by the sender's signing key (default: signed by the server if it holds the key; otherwise the message becomes unsigned)"),
+		),
+	)
+
+	s.mcpServer.AddTool(editTool, s.handleBBSEdit)
+
+	// bbs_delete tool
+	deleteTool := mcp.NewTool(
+		"bbs_delete",
+		mcp.WithDescription("Delete a message you posted. It is shown as \""+db.DeletedContent+"\" and its replies stay in place; the content is kept in the revision history. Admins may delete anyone's messages."),
+		mcp.WithNumber("message_id",
+			mcp.Required(),
+			mcp.Description("The ID of the message to delete"),
+		),
+	)
+
+	s.mcpServer.AddTool(deleteTool, s.handleBBSDelete)
+}
+
+// changeableMessage loads a message the caller wants to edit or delete and
+// checks that they may: they must be able to post to its topic and be its
+// sender or an admin. It returns an error result if not.
+func (s *Server) changeableMessage(ctx context.Context, messageID int64, operation string) (*db.Message, *mcp.CallToolResult) {
+	m, err := s.db.GetMessage(messageID)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("failed to read message: %v", err))
+	}
+	if m == nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("message %d not found", messageID))
+	}
+	if denied := s.checkTopicAccess(ctx, int64(m.TopicID), db.AccessWriter, operation); denied != nil {
+		return nil, denied
+	}
+	if m.Deleted {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("message %d is deleted", messageID))
+	}
+
+	p := s.getPrincipal(ctx)
+	if !db.CanChangeMessage(p, m.Sender) {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("access denied: message %d was posted by %s; only its sender or an admin may %s it", messageID, m.Sender, operation))
+	}
+	return m, nil
+}
+
+// handleBBSEdit handles the bbs_edit tool.
+func (s *Server) handleBBSEdit(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	messageID, err := req.RequireFloat("message_id")
+	if err != nil {
+		return mcp.NewToolResultError("message_id is required and must be a number"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content is required and must be a string"), nil
+	}
+
+	m, denied := s.changeableMessage(ctx, int64(messageID), "edit")
+	if denied != nil {
+		return denied, nil
+	}
+
+	p := s.getPrincipal(ctx)
+	topicID := int64(m.TopicID)
+
+	content, redactions, refused := s.redactContent(p.Agent, db.RedactionSourcePost, topicID, content)
+	if refused != nil {
+		return refused, nil
+	}
+
+	clientSignature := req.GetString("signature", "")
+	if clientSignature != "" && len(redactions) > 0 {
+		return s.refuseSignedRedaction(p.Agent, topicID, redactions), nil
+	}
+
+	// The signature is the sender's, so only their own edits are signed by
+	// the server
+	signature := clientSignature
+	if p.Agent == m.Sender {
+		signature, err = s.signPost(ctx, topicID, m.Sender, content, int64(m.ReplyTo), clientSignature)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	if err := s.db.EditMessage(int64(messageID), p, content, signature); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to edit message: %v", err)), nil
+	}
+	s.recordRedactions(p.Agent, db.RedactionSourcePost, topicID, int64(messageID), redactions)
+
+	s.sendResourceListChanged(ctx)
+
+	text := fmt.Sprintf("Message %d edited", int64(messageID))
+	if signature != "" {
+		text += " (signed)"
+	}
+	mentioned, err := s.db.GetMessageMentions(int64(messageID))
+	if err != nil {
+		log.Printf("Warning: failed to read mentions: %v", err)
+	}
+	if len(mentioned) > 0 {
+		text += fmt.Sprintf("\nMentioned: %s", strings.Join(mentioned, ", "))
+	}
+	text += redactionNote(redactions)
+	return mcp.NewToolResultText(text), nil
+}
+
+// handleBBSDelete handles the bbs_delete tool.
+func (s *Server) handleBBSDelete(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	messageID, err := req.RequireFloat("message_id")
+	if err != nil {
+		return mcp.NewToolResultError("message_id is required and must be a number"), nil
+	}
+
+	if _, denied := s.changeableMessage(ctx, int64(messageID), "delete"); denied != nil {
+		return denied, nil
+	}
+
+	if err := s.db.DeleteMessage(int64(messageID), s.getPrincipal(ctx)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete message: %v", err)), nil
+	}
+
+	s.sendResourceListChanged(ctx)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Message %d deleted", int64(messageID))), nil
+}
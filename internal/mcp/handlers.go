@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -40,26 +42,58 @@ func (s *Server) handleBBSPost(ctx context.Context, req mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError("content is required and must be a string"), nil
 	}
 
-	sender := s.getSender()
+	if denied := s.checkTopicAccess(ctx, int64(topicID), db.AccessWriter, "post"); denied != nil {
+		return denied, nil
+	}
+
+	sender := s.getSender(ctx)
+	replyTo := int64(req.GetFloat("reply_to", 0))
+
+	content, redactions, refused := s.redactContent(sender, db.RedactionSourcePost, int64(topicID), content)
+	if refused != nil {
+		return refused, nil
+	}
 
-	id, err := s.db.PostMessage(int64(topicID), sender, content)
+	// A client's signature covers what it sent, so masking would break it
+	clientSignature := req.GetString("signature", "")
+	if clientSignature != "" && len(redactions) > 0 {
+		return s.refuseSignedRedaction(sender, int64(topicID), redactions), nil
+	}
+
+	signature, err := s.signPost(ctx, int64(topicID), sender, content, replyTo, clientSignature)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to post message: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	if s.notifier != nil {
-		s.notifier.NotifyAll(db.Notification{
-			AgentID:   sender,
-			TopicID:   int64(topicID),
-			Message:   content,
-			Timestamp: time.Now(),
-		})
+	id, err := s.db.PostSignedReply(int64(topicID), sender, content, replyTo, signature)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to post message: %v", err)), nil
 	}
+	s.recordRedactions(sender, db.RedactionSourcePost, int64(topicID), id, redactions)
+
+	// Waiters are woken by the event feed, which also sees posts from other
+	// processes; poke it so local waiters don't wait for the next poll.
+	s.feed.Poke()
 
 	// Send resource list changed notification
 	s.sendResourceListChanged(ctx)
 
-	return mcp.NewToolResultText(fmt.Sprintf("Message posted with ID: %d", id)), nil
+	text := fmt.Sprintf("Message posted with ID: %d", id)
+	if replyTo != 0 {
+		text += fmt.Sprintf(" (reply to %d)", replyTo)
+	}
+	if signature != "" {
+		text += " (signed)"
+	}
+	mentioned, err := s.db.GetMessageMentions(id)
+	if err != nil {
+		log.Printf("Warning: failed to read mentions: %v", err)
+	}
+	if len(mentioned) > 0 {
+		text += fmt.Sprintf("\nMentioned: %s", strings.Join(mentioned, ", "))
+	}
+	text += redactionNote(redactions)
+	return mcp.NewToolResultText(text), nil
 }
 
 // handleBBSRead handles the bbs_read tool.
@@ -69,7 +103,14 @@ func (s *Server) handleBBSRead(ctx context.Context, req mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError("topic_id is required and must be a number"), nil
 	}
 
+	if denied := s.checkTopicAccess(ctx, int64(topicID), db.AccessReader, "read"); denied != nil {
+		return denied, nil
+	}
+
 	limit := int(req.GetFloat("limit", 10))
+	if limit <= 0 {
+		limit = 10
+	}
 
 	messages, err := s.db.GetMessages(int64(topicID), limit)
 	if err != nil {
@@ -80,6 +121,31 @@ func (s *Server) handleBBSRead(ctx context.Context, req mcp.CallToolRequest) (*m
 		return mcp.NewToolResultText("No messages found"), nil
 	}
 
+	// If the latest messages don't reach back to the read cursor, return the
+	// oldest unread ones instead, so none are marked read without being seen
+	sender := s.getSender(ctx)
+	cursor, err := s.db.GetReadCursor(sender, int64(topicID))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read messages: %v", err)), nil
+	}
+	if int64(messages[len(messages)-1].ID) > cursor {
+		unread, err := s.db.GetMessagesSince(db.MessageQuery{AfterID: cursor, TopicIDs: []int64{int64(topicID)}, Limit: limit})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read messages: %v", err)), nil
+		}
+		if len(unread) > 0 && unread[0].ID < messages[len(messages)-1].ID {
+			messages = messages[:0]
+			for i := len(unread) - 1; i >= 0; i-- {
+				messages = append(messages, unread[i])
+			}
+		}
+	}
+
+	// Messages are newest first; everything up to the newest one has now been seen
+	if err := s.db.AdvanceReadCursor(sender, int64(topicID), int64(messages[0].ID)); err != nil {
+		log.Printf("Warning: failed to advance read cursor: %v", err)
+	}
+
 	// Format messages as JSON
 	data, err := json.MarshalIndent(messages, "", "  ")
 	if err != nil {
@@ -89,15 +155,326 @@ func (s *Server) handleBBSRead(ctx context.Context, req mcp.CallToolRequest) (*m
 	return mcp.NewToolResultText(string(data)), nil
 }
 
+// handleBBSReadThread handles the bbs_read_thread tool.
+func (s *Server) handleBBSReadThread(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	messageID, err := req.RequireFloat("message_id")
+	if err != nil {
+		return mcp.NewToolResultError("message_id is required and must be a number"), nil
+	}
+
+	rootID := int64(messageID)
+	if req.GetBool("whole_thread", false) {
+		rootID, err = s.db.GetThreadRootID(rootID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read thread: %v", err)), nil
+		}
+	}
+
+	thread, err := s.db.GetThread(rootID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read thread: %v", err)), nil
+	}
+	if thread == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("message %d not found", rootID)), nil
+	}
+	if denied := s.checkTopicAccess(ctx, int64(thread.TopicID), db.AccessReader, "read_thread"); denied != nil {
+		return denied, nil
+	}
+
+	data, err := json.MarshalIndent(thread, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal thread: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleBBSSendDM handles the bbs_send_dm tool.
+func (s *Server) handleBBSSendDM(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	to, err := req.RequireString("to")
+	if err != nil || strings.TrimSpace(to) == "" {
+		return mcp.NewToolResultError("to is required and must be a string"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content is required and must be a string"), nil
+	}
+
+	sender := s.getSender(ctx)
+	if to == sender {
+		return mcp.NewToolResultError("cannot send a direct message to yourself"), nil
+	}
+
+	content, redactions, refused := s.redactContent(sender, db.RedactionSourceDM, 0, content)
+	if refused != nil {
+		return refused, nil
+	}
+
+	id, err := s.db.SendDirectMessage(sender, to, content)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to send direct message: %v", err)), nil
+	}
+	s.recordRedactions(sender, db.RedactionSourceDM, 0, id, redactions)
+
+	// Only the recipient's waiters are woken, via the event feed
+	s.feed.Poke()
+
+	return mcp.NewToolResultText(fmt.Sprintf("Direct message sent to %s with ID: %d", to, id) + redactionNote(redactions)), nil
+}
+
+// handleBBSReadDMs handles the bbs_read_dms tool.
+func (s *Server) handleBBSReadDMs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	agent := s.getSender(ctx)
+
+	messages, err := s.db.GetDirectMessages(db.DMQuery{
+		Agent:      agent,
+		With:       req.GetString("with", ""),
+		UnreadOnly: req.GetBool("unread_only", false),
+		Limit:      int(req.GetFloat("limit", 20)),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read direct messages: %v", err)), nil
+	}
+
+	if len(messages) == 0 {
+		return mcp.NewToolResultText("No direct messages found"), nil
+	}
+
+	if _, err := s.db.MarkDirectMessagesRead(agent, directMessageIDs(messages)); err != nil {
+		log.Printf("Warning: failed to mark direct messages read: %v", err)
+	}
+
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal direct messages: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// directMessageIDs returns the IDs of messages.
+func directMessageIDs(messages []db.DirectMessage) []int64 {
+	ids := make([]int64, len(messages))
+	for i, m := range messages {
+		ids[i] = int64(m.ID)
+	}
+	return ids
+}
+
+// handleBBSListTopics handles the bbs_list_topics tool.
+func (s *Server) handleBBSListTopics(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	reader := s.getPrincipal(ctx)
+	opts := db.TopicListOptions{
+		Agent:      reader.Agent,
+		Reader:     &reader,
+		TitleQuery: req.GetString("title", ""),
+		HasUnread:  req.GetBool("has_unread", false),
+		Cursor:     int64(req.GetFloat("cursor", 0)),
+		Limit:      int(req.GetFloat("limit", 20)),
+	}
+
+	if activeSince := req.GetString("active_since", ""); activeSince != "" {
+		t, err := parseTime(activeSince)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid active_since: %v", err)), nil
+		}
+		opts.ActiveSince = t
+	}
+
+	topics, nextCursor, err := s.db.ListTopicStats(opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list topics: %v", err)), nil
+	}
+
+	if topics == nil {
+		topics = []db.TopicStats{}
+	}
+
+	response := map[string]interface{}{
+		"topics":      topics,
+		"next_cursor": nextCursor,
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal topics: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleBBSSearch handles the bbs_search tool.
+func (s *Server) handleBBSSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := req.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError("query is required and must be a string"), nil
+	}
+
+	reader := s.getPrincipal(ctx)
+	filter := db.SearchFilter{
+		Reader:           &reader,
+		Sender:           req.GetString("sender", ""),
+		TopicID:          int64(req.GetFloat("topic_id", 0)),
+		IncludeSummaries: req.GetBool("include_summaries", true),
+		Limit:            int(req.GetFloat("limit", 20)),
+	}
+
+	if since := req.GetString("since", ""); since != "" {
+		if filter.Since, err = parseTime(since); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid since: %v", err)), nil
+		}
+	}
+	if until := req.GetString("until", ""); until != "" {
+		if filter.Until, err = parseTime(until); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid until: %v", err)), nil
+		}
+	}
+
+	results, err := s.db.SearchMessages(query, filter)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search: %v", err)), nil
+	}
+
+	if len(results) == 0 {
+		return mcp.NewToolResultText("No matches found"), nil
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleBBSGetSummary handles the bbs_get_summary tool.
+func (s *Server) handleBBSGetSummary(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	topicID, err := req.RequireFloat("topic_id")
+	if err != nil {
+		return mcp.NewToolResultError("topic_id is required and must be a number"), nil
+	}
+
+	if denied := s.checkTopicAccess(ctx, int64(topicID), db.AccessReader, "read_summary"); denied != nil {
+		return denied, nil
+	}
+
+	summary, err := s.db.GetLatestSummary(int64(topicID))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get summary: %v", err)), nil
+	}
+	if summary == nil {
+		return mcp.NewToolResultText(fmt.Sprintf("No summary found for topic %d", int64(topicID))), nil
+	}
+
+	data, err := json.MarshalIndent(summaryResponse(summary), "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal summary: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// summaryResponse is the JSON form of a summary returned by bbs_get_summary
+// and the hub://topics/{id}/summary resource. Summaries written before
+// structured summaries only have text.
+func summaryResponse(summary *db.TopicSummary) map[string]interface{} {
+	response := map[string]interface{}{
+		"summary_id":       summary.ID,
+		"topic_id":         summary.TopicID,
+		"created_at":       summary.CreatedAt,
+		"is_mock":          summary.IsMock,
+		"first_message_id": summary.FirstMessageID,
+		"last_message_id":  summary.LastMessageID,
+		"message_count":    summary.MessageCount,
+		"structured":       summary.Sections != nil,
+		"text":             summary.SummaryText,
+	}
+
+	if sections := summary.Sections; sections != nil {
+		actionItems := make([]map[string]string, 0, len(sections.ActionItems))
+		for _, item := range sections.ActionItems {
+			actionItems = append(actionItems, map[string]string{"task": item.Task, "owner": item.Owner})
+		}
+		response["overview"] = sections.Overview
+		response["decisions"] = db.EmptyIfNil(sections.Decisions)
+		response["action_items"] = actionItems
+		response["open_questions"] = db.EmptyIfNil(sections.OpenQuestions)
+		response["risks"] = db.EmptyIfNil(sections.Risks)
+	}
+
+	return response
+}
+
+// parseTime parses a timestamp given as RFC3339 or SQLite's default format (UTC).
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02 15:04:05", value)
+}
+
+// handleBBSMarkRead handles the bbs_mark_read tool.
+func (s *Server) handleBBSMarkRead(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	topicID, err := req.RequireFloat("topic_id")
+	if err != nil {
+		return mcp.NewToolResultError("topic_id is required and must be a number"), nil
+	}
+
+	if denied := s.checkTopicAccess(ctx, int64(topicID), db.AccessReader, "mark_read"); denied != nil {
+		return denied, nil
+	}
+
+	sender := s.getSender(ctx)
+	messageID := int64(req.GetFloat("message_id", 0))
+
+	if messageID > 0 {
+		err = s.db.AdvanceReadCursor(sender, int64(topicID), messageID)
+	} else {
+		messageID, err = s.db.MarkTopicRead(sender, int64(topicID))
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to mark as read: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Topic %d marked as read up to message %d", int64(topicID), messageID)), nil
+}
+
 // handleCheckHubStatus handles the check_hub_status tool.
 func (s *Server) handleCheckHubStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sender := s.getSender()
+	reader := s.getPrincipal(ctx)
+	sender := reader.Agent
 
-	unreadCount, err := s.db.CountUnreadMessages(sender)
+	unreadByTopic, err := s.db.CountUnreadByTopic(sender, &reader)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to count unread messages: %v", err)), nil
 	}
 
+	var unreadCount int64
+	topicsUnread := make([]map[string]interface{}, 0, len(unreadByTopic))
+	for _, u := range unreadByTopic {
+		unreadCount += u.UnreadCount
+		topicsUnread = append(topicsUnread, map[string]interface{}{
+			"topic_id":             u.TopicID,
+			"title":                u.Title,
+			"unread_count":         u.UnreadCount,
+			"last_read_message_id": u.LastReadMessageID,
+		})
+	}
+
+	unreadDMs, err := s.db.CountUnreadDirectMessages(sender)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to count unread direct messages: %v", err)), nil
+	}
+
+	mentions, err := s.db.GetUnreadMentions(sender, &reader, 20)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get mentions: %v", err)), nil
+	}
+	if mentions == nil {
+		mentions = []db.Mention{}
+	}
+
 	if err := s.db.UpdateAgentCheckTime(sender); err != nil {
 		fmt.Printf("Warning: failed to update check time: %v\n", err)
 	}
@@ -108,11 +485,24 @@ func (s *Server) handleCheckHubStatus(ctx context.Context, req mcp.CallToolReque
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get team presence: %v", err)), nil
 	}
 
+	// Advisory locks held by anyone, so agents see who is editing what
+	locks, err := s.db.ListLocks("")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list locks: %v", err)), nil
+	}
+	if locks == nil {
+		locks = []db.Lock{}
+	}
+
 	// Build response
 	response := map[string]interface{}{
-		"has_new_activity": unreadCount > 0,
+		"has_new_activity": unreadCount > 0 || unreadDMs > 0,
 		"unread_count":     unreadCount,
+		"unread_by_topic":  topicsUnread,
+		"unread_dms":       unreadDMs,
+		"mentions":         mentions,
 		"team_presence":    presences,
+		"locks":            locks,
 	}
 
 	// Format as JSON
@@ -127,13 +517,16 @@ func (s *Server) handleCheckHubStatus(ctx context.Context, req mcp.CallToolReque
 	if unreadCount > 0 {
 		result += "\n\n【重要：連携ガイドライン】BBSに未読メッセージがあります。リソース `guidelines://agent-collaboration` に基づき、現在の作業を保存し、最優先で `bbs_read` を実行してください。確認後は `update_status` で状況を報告してください。"
 	}
+	if unreadDMs > 0 {
+		result += "\n\n【重要】未読のダイレクトメッセージがあります。`bbs_read_dms` で確認してください。"
+	}
 
 	return mcp.NewToolResultText(result), nil
 }
 
 // handleUpdateStatus handles the update_status tool.
 func (s *Server) handleUpdateStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sender := s.getSender()
+	sender := s.getSender(ctx)
 
 	status, err := req.RequireString("status")
 	if err != nil {
@@ -162,11 +555,27 @@ func (s *Server) handleUpdateStatus(ctx context.Context, req mcp.CallToolRequest
 	return mcp.NewToolResultText(fmt.Sprintf("Status updated: %s%s", status, topicInfo)), nil
 }
 
+// maxWaitBatch is the maximum number of messages returned by one wait_notify call.
+const maxWaitBatch = 100
+
+// waitBatch holds what a wait_notify call delivers and the cursors to resume from.
+type waitBatch struct {
+	messages      []db.Message
+	lastMessageID int64
+	dms           []db.DirectMessage
+	lastDMID      int64
+}
+
 // handleWaitNotify handles the wait_notify tool.
+// It returns every message newer than since_message_id that matches the filters,
+// plus direct messages to the agent newer than since_dm_id, waiting until at
+// least one arrives or the timeout expires.
 func (s *Server) handleWaitNotify(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	agentID, err := req.RequireString("agent_id")
-	if err != nil {
-		return mcp.NewToolResultError("agent_id is required and must be a string"), nil
+	// Whose messages, direct messages and read state are used follows the
+	// caller's identity; agent_id is only checked against it
+	agentID := s.getSender(ctx)
+	if claimed := req.GetString("agent_id", ""); claimed != "" && claimed != agentID {
+		return mcp.NewToolResultError(fmt.Sprintf("agent_id %s does not match your identity %s; register as that agent with bbs_register_agent first", claimed, agentID)), nil
 	}
 
 	timeoutSec := int(req.GetFloat("timeout_sec", 180))
@@ -174,50 +583,124 @@ func (s *Server) handleWaitNotify(ctx context.Context, req mcp.CallToolRequest)
 		timeoutSec = 180
 	}
 
-	if s.notifier == nil {
-		s.notifier = db.NewNotifier()
+	reader := s.getPrincipal(ctx)
+	query := db.MessageQuery{
+		ExcludeSender: agentID,
+		Limit:         maxWaitBatch,
+		Reader:        &reader,
+	}
+	for _, id := range req.GetIntSlice("topic_ids", nil) {
+		query.TopicIDs = append(query.TopicIDs, int64(id))
 	}
+	mentionsOnly := req.GetBool("mentions_only", false)
+	if mentionsOnly {
+		query.Mention = agentID
+	}
+	includeDMs := req.GetBool("include_dms", true)
 
-	ch := s.notifier.Register(agentID)
-	defer s.notifier.Unregister(agentID)
+	// Subscribe before reading the cursors so nothing posted in between is missed
+	ch, unsubscribe, err := s.feed.Subscribe(agentID, db.SubscribeOptions{MentionsOnly: mentionsOnly})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to wait for notifications: %v", err)), nil
+	}
+	defer unsubscribe()
 
-	timeout := time.After(time.Duration(timeoutSec) * time.Second)
+	query.AfterID = int64(req.GetFloat("since_message_id", -1))
+	if query.AfterID < 0 {
+		query.AfterID, err = s.db.LatestMessageID()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get latest message: %v", err)), nil
+		}
+	}
 
-	select {
-	case notification := <-ch:
-		response := map[string]interface{}{
-			"has_new": true,
-			"status":  "new_messages",
-			"message": fmt.Sprintf("New message on topic %d: %s", notification.TopicID, notification.Message),
+	dmAfterID := int64(req.GetFloat("since_dm_id", -1))
+	if dmAfterID < 0 {
+		dmAfterID, err = s.db.LatestDirectMessageID(agentID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get latest direct message: %v", err)), nil
 		}
-		data, err := json.MarshalIndent(response, "", "  ")
+	}
+
+	timeout := time.After(time.Duration(timeoutSec) * time.Second)
+
+	for {
+		batch := waitBatch{lastMessageID: query.AfterID, lastDMID: dmAfterID}
+
+		batch.messages, err = s.db.GetMessagesSince(query)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read new messages: %v", err)), nil
 		}
-		return mcp.NewToolResultText(string(data)), nil
-	case <-timeout:
-		response := map[string]interface{}{
-			"has_new": false,
-			"status":  "timeout",
-			"message": fmt.Sprintf("No new messages within %d seconds", timeoutSec),
+		if includeDMs {
+			batch.dms, err = s.db.GetDirectMessagesSince(agentID, dmAfterID, maxWaitBatch)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to read new direct messages: %v", err)), nil
+			}
 		}
-		data, err := json.MarshalIndent(response, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+		if len(batch.messages) > 0 || len(batch.dms) > 0 {
+			summary := fmt.Sprintf("%d new message(s)", len(batch.messages))
+			if len(batch.dms) > 0 {
+				summary += fmt.Sprintf(", %d new direct message(s)", len(batch.dms))
+			}
+			return s.waitResult(agentID, "new_messages", summary, batch)
 		}
-		return mcp.NewToolResultText(string(data)), nil
-	case <-ctx.Done():
-		response := map[string]interface{}{
-			"has_new": false,
-			"status":  "cancelled",
-			"message": "Wait operation cancelled",
+
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				// Another wait_notify call for the same agent replaced this one
+				return mcp.NewToolResultError("wait superseded by another wait_notify call for the same agent"), nil
+			}
+			// Notifications only signal activity; the queries above decide what is new
+		case <-timeout:
+			return s.waitResult(agentID, "timeout", fmt.Sprintf("No new messages within %d seconds", timeoutSec), batch)
+		case <-ctx.Done():
+			return s.waitResult(agentID, "cancelled", "Wait operation cancelled", batch)
 		}
-		data, err := json.MarshalIndent(response, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+}
+
+// waitResult builds the wait_notify response and marks delivered messages as read.
+func (s *Server) waitResult(agentID, status, message string, batch waitBatch) (*mcp.CallToolResult, error) {
+	latestByTopic := make(map[int64]int64)
+	for _, m := range batch.messages {
+		batch.lastMessageID = int64(m.ID)
+		latestByTopic[int64(m.TopicID)] = int64(m.ID)
+	}
+	for topicID, messageID := range latestByTopic {
+		if err := s.db.AdvanceReadCursor(agentID, topicID, messageID); err != nil {
+			log.Printf("Warning: failed to advance read cursor: %v", err)
 		}
-		return mcp.NewToolResultText(string(data)), nil
 	}
+
+	if len(batch.dms) > 0 {
+		batch.lastDMID = int64(batch.dms[len(batch.dms)-1].ID)
+		if _, err := s.db.MarkDirectMessagesRead(agentID, directMessageIDs(batch.dms)); err != nil {
+			log.Printf("Warning: failed to mark direct messages read: %v", err)
+		}
+	}
+
+	if batch.messages == nil {
+		batch.messages = []db.Message{}
+	}
+	if batch.dms == nil {
+		batch.dms = []db.DirectMessage{}
+	}
+
+	response := map[string]interface{}{
+		"has_new":         len(batch.messages) > 0 || len(batch.dms) > 0,
+		"status":          status,
+		"message":         message,
+		"messages":        batch.messages,
+		"last_message_id": batch.lastMessageID,
+		"direct_messages": batch.dms,
+		"last_dm_id":      batch.lastDMID,
+		"more_available":  len(batch.messages) == maxWaitBatch || len(batch.dms) == maxWaitBatch,
+	}
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
 }
 
 // handleRegisterAgent handles the bbs_register_agent tool.
@@ -241,12 +724,23 @@ func (s *Server) handleRegisterAgent(ctx context.Context, req mcp.CallToolReques
 		topicID = &tid
 	}
 
+	// With an API token, the identity is the one the token is bound to
+	token := tokenFromContext(ctx)
+	if token != nil {
+		if name != token.Agent {
+			return mcp.NewToolResultError(fmt.Sprintf("your API token is bound to agent %q; register as %q", token.Agent, token.Agent)), nil
+		}
+		role = token.Role
+	}
+	if token == nil && strings.EqualFold(name, db.OrchestratorAgent) {
+		return mcp.NewToolResultError(fmt.Sprintf("%q is reserved for the orchestrator's posts", db.OrchestratorAgent)), nil
+	}
+
 	if err := s.db.UpsertAgentPresence(name, role); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to register agent: %v", err)), nil
 	}
 
-	s.CurrentSender = name
-	s.DefaultRole = role
+	s.setIdentity(ctx, name, role)
 
 	if err := s.db.UpdateAgentStatus(name, status, topicID); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to set initial status: %v", err)), nil
@@ -0,0 +1,356 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// kvWatchInterval is how often a server checks for blackboard writes made by
+// any process to notify its clients.
+const kvWatchInterval = db.DefaultFeedInterval
+
+// kvResourceURI returns the URI of the resource listing a namespace.
+func kvResourceURI(namespace string) string {
+	return "hub://kv/" + namespace
+}
+
+// registerKVTools registers the key-value blackboard tools.
+func (s *Server) registerKVTools() {
+	namespaceOption := mcp.WithString("namespace",
+		mcp.Required(),
+		mcp.Description("Namespace grouping related keys, e.g. a project or topic name"),
+	)
+	keyOption := mcp.WithString("key",
+		mcp.Required(),
+		mcp.Description("The key"),
+	)
+	valueOption := mcp.WithString("value",
+		mcp.Description("The value; use JSON for structured values"),
+	)
+	ttlOption := mcp.WithNumber("ttl_sec",
+		mcp.Description("Delete the key after this many seconds (default: never)"),
+	)
+	deleteOption := mcp.WithBoolean("delete",
+		mcp.Description("Delete the key instead of setting it"),
+	)
+
+	// kv_get tool
+	getTool := mcp.NewTool(
+		"kv_get",
+		mcp.WithDescription("Read a key from the shared blackboard, with its version for kv_cas"),
+		namespaceOption,
+		keyOption,
+	)
+
+	s.mcpServer.AddTool(getTool, s.handleKVGet)
+
+	// kv_set tool
+	setTool := mcp.NewTool(
+		"kv_set",
+		mcp.WithDescription("Set or delete a key on the shared blackboard, overwriting any current value"),
+		namespaceOption,
+		keyOption,
+		valueOption,
+		ttlOption,
+		deleteOption,
+	)
+
+	s.mcpServer.AddTool(setTool, s.handleKVSet)
+
+	// kv_cas tool
+	casTool := mcp.NewTool(
+		"kv_cas",
+		mcp.WithDescription("Set or delete a key only if it is still at expected_version (compare-and-swap). On a conflict, returns swapped=false with the current entry."),
+		namespaceOption,
+		keyOption,
+		mcp.WithNumber("expected_version",
+			mcp.Required(),
+			mcp.Description("Version from kv_get; 0 to create a key that must not exist yet"),
+		),
+		valueOption,
+		ttlOption,
+		deleteOption,
+	)
+
+	s.mcpServer.AddTool(casTool, s.handleKVCAS)
+
+	// kv_list tool
+	listTool := mcp.NewTool(
+		"kv_list",
+		mcp.WithDescription("List keys on the shared blackboard"),
+		mcp.WithString("namespace",
+			mcp.Description("Only keys in this namespace (default: all namespaces)"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Only keys starting with this prefix"),
+		),
+	)
+
+	s.mcpServer.AddTool(listTool, s.handleKVList)
+
+	// kv_history tool
+	historyTool := mcp.NewTool(
+		"kv_history",
+		mcp.WithDescription("Show who changed a key (or a whole namespace) and when, newest first"),
+		namespaceOption,
+		mcp.WithString("key",
+			mcp.Description("The key (default: every key in the namespace)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of changes to return (default: 50)"),
+		),
+	)
+
+	s.mcpServer.AddTool(historyTool, s.handleKVHistory)
+}
+
+// registerKVResources registers the hub://kv/{namespace} resources.
+func (s *Server) registerKVResources() {
+	kvTemplate := mcp.NewResourceTemplate(
+		"hub://kv/{namespace}",
+		"Blackboard Namespace",
+		mcp.WithTemplateDescription("Live keys in a namespace of the shared blackboard; notifications/resources/updated is sent when it changes"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	s.mcpServer.AddResourceTemplate(kvTemplate, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		var namespace string
+		if values, ok := request.Params.Arguments["namespace"].([]string); ok && len(values) == 1 {
+			namespace = values[0]
+		}
+		if namespace == "" {
+			return nil, fmt.Errorf("namespace is required")
+		}
+
+		entries, err := s.db.ListKV(namespace, "")
+		if err != nil {
+			return nil, err
+		}
+		if entries == nil {
+			entries = []db.KVEntry{}
+		}
+
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal keys: %w", err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		}, nil
+	})
+}
+
+// handleKVGet handles the kv_get tool.
+func (s *Server) handleKVGet(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError("namespace is required and must be a string"), nil
+	}
+	key, err := req.RequireString("key")
+	if err != nil {
+		return mcp.NewToolResultError("key is required and must be a string"), nil
+	}
+
+	entry, err := s.db.GetKV(namespace, key)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get key: %v", err)), nil
+	}
+	if entry == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("key %q not found in namespace %q", key, namespace)), nil
+	}
+
+	return marshalKV(entry)
+}
+
+// handleKVSet handles the kv_set tool.
+func (s *Server) handleKVSet(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	w, result := s.kvWrite(ctx, req)
+	if result != nil {
+		return result, nil
+	}
+
+	entry, _, err := s.db.WriteKV(w)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write key: %v", err)), nil
+	}
+	if w.Delete {
+		return mcp.NewToolResultText(fmt.Sprintf("Key %q deleted from namespace %q", w.Key, w.Namespace)), nil
+	}
+
+	return marshalKV(entry)
+}
+
+// handleKVCAS handles the kv_cas tool.
+func (s *Server) handleKVCAS(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	expected, err := req.RequireFloat("expected_version")
+	if err != nil {
+		return mcp.NewToolResultError("expected_version is required and must be a number"), nil
+	}
+
+	w, result := s.kvWrite(ctx, req)
+	if result != nil {
+		return result, nil
+	}
+	version := int64(expected)
+	w.ExpectedVersion = &version
+
+	entry, swapped, err := s.db.WriteKV(w)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write key: %v", err)), nil
+	}
+
+	response := map[string]interface{}{
+		"swapped": swapped,
+		"entry":   entry,
+	}
+	if !swapped {
+		if entry != nil {
+			response["message"] = fmt.Sprintf("key is at version %d, not %d; re-read it and retry", entry.Version, version)
+		} else {
+			response["message"] = "key does not exist; use expected_version 0 to create it"
+		}
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// kvWrite reads the arguments shared by kv_set and kv_cas. It returns a
+// tool error result if they are invalid.
+func (s *Server) kvWrite(ctx context.Context, req mcp.CallToolRequest) (db.KVWrite, *mcp.CallToolResult) {
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return db.KVWrite{}, mcp.NewToolResultError("namespace is required and must be a string")
+	}
+	key, err := req.RequireString("key")
+	if err != nil {
+		return db.KVWrite{}, mcp.NewToolResultError("key is required and must be a string")
+	}
+
+	w := db.KVWrite{
+		Namespace: namespace,
+		Key:       key,
+		Value:     req.GetString("value", ""),
+		Author:    s.getSender(ctx),
+		TTL:       time.Duration(req.GetFloat("ttl_sec", 0) * float64(time.Second)),
+		Delete:    req.GetBool("delete", false),
+	}
+	if _, ok := req.GetArguments()["value"]; !ok && !w.Delete {
+		return db.KVWrite{}, mcp.NewToolResultError("value is required unless delete is set")
+	}
+	return w, nil
+}
+
+// handleKVList handles the kv_list tool.
+func (s *Server) handleKVList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	entries, err := s.db.ListKV(req.GetString("namespace", ""), req.GetString("prefix", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list keys: %v", err)), nil
+	}
+
+	if entries == nil {
+		entries = []db.KVEntry{}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal keys: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleKVHistory handles the kv_history tool.
+func (s *Server) handleKVHistory(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError("namespace is required and must be a string"), nil
+	}
+
+	changes, err := s.db.GetKVHistory(namespace, req.GetString("key", ""), int(req.GetFloat("limit", 50)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get key history: %v", err)), nil
+	}
+
+	if changes == nil {
+		changes = []db.KVChange{}
+	}
+
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal key history: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// marshalKV returns a blackboard entry as a JSON tool result.
+func marshalKV(entry *db.KVEntry) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal key: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// startKVWatcher starts notifying clients of blackboard writes. The returned
+// function stops it.
+func (s *Server) startKVWatcher() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.runKVWatcher(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// runKVWatcher sends notifications/resources/updated for every namespace
+// written to, by this or any other process, until ctx is done.
+func (s *Server) runKVWatcher(ctx context.Context) {
+	lastID, err := s.db.LatestKVChangeID()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	ticker := time.NewTicker(kvWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var namespaces []string
+		namespaces, lastID, err = s.db.GetKVNamespacesChangedSince(lastID)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		for _, namespace := range namespaces {
+			s.mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{
+				"uri": kvResourceURI(namespace),
+			})
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// registerLockTools registers the advisory lock tools.
+func (s *Server) registerLockTools() {
+	ttlDescription := fmt.Sprintf("Lease duration in seconds (default: %d, max: %d)",
+		int(db.DefaultLockTTL.Seconds()), int(db.MaxLockTTL.Seconds()))
+
+	// lock_acquire tool
+	acquireTool := mcp.NewTool(
+		"lock_acquire",
+		mcp.WithDescription("Take an advisory lock on a file or other resource before changing it. The lock expires after ttl_sec unless renewed. If another agent holds it, returns acquired=false with the current holder."),
+		mcp.WithString("resource",
+			mcp.Required(),
+			mcp.Description("Name of the resource, e.g. a repository-relative file path"),
+		),
+		mcp.WithNumber("ttl_sec",
+			mcp.Description(ttlDescription),
+		),
+		mcp.WithString("note",
+			mcp.Description("What you are doing with the resource"),
+		),
+	)
+
+	s.mcpServer.AddTool(acquireTool, s.handleLockAcquire)
+
+	// lock_renew tool
+	renewTool := mcp.NewTool(
+		"lock_renew",
+		mcp.WithDescription("Extend the lease on a lock you hold. Fails once the lease has expired; acquire the lock again instead"),
+		mcp.WithString("resource",
+			mcp.Required(),
+			mcp.Description("Name of the locked resource"),
+		),
+		mcp.WithNumber("ttl_sec",
+			mcp.Description("New lease duration in seconds from now (default: same as lock_acquire)"),
+		),
+	)
+
+	s.mcpServer.AddTool(renewTool, s.handleLockRenew)
+
+	// lock_release tool
+	releaseTool := mcp.NewTool(
+		"lock_release",
+		mcp.WithDescription("Release a lock you hold"),
+		mcp.WithString("resource",
+			mcp.Required(),
+			mcp.Description("Name of the locked resource"),
+		),
+	)
+
+	s.mcpServer.AddTool(releaseTool, s.handleLockRelease)
+
+	// lock_list tool
+	listTool := mcp.NewTool(
+		"lock_list",
+		mcp.WithDescription("List held locks with their holders and expiry"),
+		mcp.WithString("holder",
+			mcp.Description("Only locks held by this agent"),
+		),
+		mcp.WithBoolean("mine",
+			mcp.Description("Only locks you hold"),
+		),
+	)
+
+	s.mcpServer.AddTool(listTool, s.handleLockList)
+}
+
+// lockTTL reads the ttl_sec argument.
+func lockTTL(req mcp.CallToolRequest) time.Duration {
+	return time.Duration(req.GetFloat("ttl_sec", db.DefaultLockTTL.Seconds()) * float64(time.Second))
+}
+
+// handleLockAcquire handles the lock_acquire tool.
+func (s *Server) handleLockAcquire(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	resource, err := req.RequireString("resource")
+	if err != nil {
+		return mcp.NewToolResultError("resource is required and must be a string"), nil
+	}
+
+	lock, acquired, err := s.db.AcquireLock(resource, s.getSender(ctx), req.GetString("note", ""), lockTTL(req))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to acquire lock: %v", err)), nil
+	}
+
+	response := map[string]interface{}{
+		"acquired": acquired,
+		"lock":     lock,
+	}
+	if !acquired {
+		response["message"] = fmt.Sprintf("%s is locked by %s for another %d seconds; coordinate with them or try again later",
+			lock.Resource, lock.Holder, lock.ExpiresInSec)
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleLockRenew handles the lock_renew tool.
+func (s *Server) handleLockRenew(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	resource, err := req.RequireString("resource")
+	if err != nil {
+		return mcp.NewToolResultError("resource is required and must be a string"), nil
+	}
+
+	lock, err := s.db.RenewLock(resource, s.getSender(ctx), lockTTL(req))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to renew lock: %v", err)), nil
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal lock: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleLockRelease handles the lock_release tool.
+func (s *Server) handleLockRelease(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	resource, err := req.RequireString("resource")
+	if err != nil {
+		return mcp.NewToolResultError("resource is required and must be a string"), nil
+	}
+
+	if err := s.db.ReleaseLock(resource, s.getSender(ctx)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to release lock: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Lock on %s released", resource)), nil
+}
+
+// handleLockList handles the lock_list tool.
+func (s *Server) handleLockList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	holder := req.GetString("holder", "")
+	if req.GetBool("mine", false) {
+		holder = s.getSender(ctx)
+	}
+
+	locks, err := s.db.ListLocks(holder)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list locks: %v", err)), nil
+	}
+
+	if locks == nil {
+		locks = []db.Lock{}
+	}
+
+	data, err := json.MarshalIndent(locks, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal locks: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// redactContent runs posted content through the redaction rules. It returns
+// the content to store and the redactions to record once the message has an
+// ID, or a tool error if a rule rejects the content.
+func (s *Server) redactContent(sender, source string, topicID int64, content string) (string, []db.Redaction, *mcp.CallToolResult) {
+	redactor, err := s.db.Redactor(s.Redaction)
+	if err != nil {
+		return "", nil, mcp.NewToolResultError(fmt.Sprintf("failed to load redaction rules: %v", err))
+	}
+
+	masked, redactions := redactor.Redact(content)
+	if rejected := db.Rejecting(redactions); len(rejected) > 0 {
+		s.recordRedactions(sender, source, topicID, 0, redactions)
+		return "", nil, mcp.NewToolResultError(fmt.Sprintf("refused: the content looks like it contains a secret (%s); remove it and try again", strings.Join(rejected, ", ")))
+	}
+	return masked, redactions, nil
+}
+
+// refuseSignedRedaction refuses client-signed content that would need
+// masking, recording its redactions as rejections.
+func (s *Server) refuseSignedRedaction(sender string, topicID int64, redactions []db.Redaction) *mcp.CallToolResult {
+	for i := range redactions {
+		redactions[i].Action = db.RedactReject
+	}
+	s.recordRedactions(sender, db.RedactionSourcePost, topicID, 0, redactions)
+	return mcp.NewToolResultError(fmt.Sprintf("refused: the content looks like it contains a secret (%s), and signed posts can't be masked; remove it and sign again", strings.Join(db.Rejecting(redactions), ", ")))
+}
+
+// recordRedactions records redaction events, logging failures.
+func (s *Server) recordRedactions(sender, source string, topicID, messageID int64, redactions []db.Redaction) {
+	if err := s.db.RecordRedactions(sender, source, topicID, messageID, redactions); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// redactionNote describes the redactions made to a post for the tool result.
+func redactionNote(redactions []db.Redaction) string {
+	if len(redactions) == 0 {
+		return ""
+	}
+	names := make([]string, len(redactions))
+	for i, r := range redactions {
+		names[i] = r.Rule
+	}
+	return fmt.Sprintf("\nRedacted: %s", strings.Join(names, ", "))
+}
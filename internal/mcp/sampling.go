@@ -0,0 +1,282 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// Client selection policies for SamplingConfig.Policy.
+const (
+	SamplingPolicyFirst      = "first"       // Ask clients in preference order, earliest connected first
+	SamplingPolicyRoundRobin = "round-robin" // Rotate the first client asked between requests
+)
+
+// Sampling worker timing. Heartbeats must be more frequent than
+// db.SamplingWorkerTTL or the orchestrator considers the worker gone.
+const (
+	samplingPollInterval      = time.Second
+	samplingHeartbeatInterval = 5 * time.Second
+	defaultSamplingTimeout    = 2 * time.Minute
+)
+
+// SamplingConfig is the operator's consent for lending the models of
+// connected MCP clients to the orchestrator through MCP sampling.
+type SamplingConfig struct {
+	AllowedClients []string      // Client names (from initialize) that may be asked, in order of preference (empty = any)
+	Policy         string        // SamplingPolicyFirst (default) or SamplingPolicyRoundRobin
+	Timeout        time.Duration // Time one client may take to answer (0 = 2 minutes)
+}
+
+// EnableSampling lets the orchestrator summarize through connected clients
+// that advertise the sampling capability. Without it, no sampling requests
+// are ever sent. The worker runs while Serve or ServeSSE is running.
+func (s *Server) EnableSampling(cfg SamplingConfig) error {
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = SamplingPolicyFirst
+	case SamplingPolicyFirst, SamplingPolicyRoundRobin:
+	default:
+		return fmt.Errorf("unknown sampling policy %q (want %s or %s)", cfg.Policy, SamplingPolicyFirst, SamplingPolicyRoundRobin)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultSamplingTimeout
+	}
+
+	s.sampling = &cfg
+	s.mcpServer.EnableSampling()
+	return nil
+}
+
+// samplingClients returns the connected sessions that may be asked to
+// sample, in the order they should be tried.
+func (s *Server) samplingClients() []server.SessionWithSampling {
+	if s.sampling == nil {
+		return nil
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	// Bucket by position in the allowlist, keeping connection order.
+	buckets := make([][]server.SessionWithSampling, max(len(s.sampling.AllowedClients), 1))
+	for _, session := range s.sessions {
+		sampler, ok := session.(server.SessionWithSampling)
+		if !ok || !session.Initialized() {
+			continue
+		}
+		info, ok := session.(server.SessionWithClientInfo)
+		if !ok || info.GetClientCapabilities().Sampling == nil {
+			continue
+		}
+
+		rank := 0
+		if len(s.sampling.AllowedClients) > 0 {
+			rank = -1
+			for i, name := range s.sampling.AllowedClients {
+				if strings.EqualFold(name, info.GetClientInfo().Name) {
+					rank = i
+					break
+				}
+			}
+			if rank < 0 {
+				continue
+			}
+		}
+		buckets[rank] = append(buckets[rank], sampler)
+	}
+
+	var clients []server.SessionWithSampling
+	for _, bucket := range buckets {
+		clients = append(clients, bucket...)
+	}
+	return clients
+}
+
+// nextSamplingClients returns the clients to try for one request. With
+// SamplingPolicyRoundRobin each request starts at the next client.
+func (s *Server) nextSamplingClients() []server.SessionWithSampling {
+	clients := s.samplingClients()
+	if s.sampling.Policy != SamplingPolicyRoundRobin || len(clients) < 2 {
+		return clients
+	}
+
+	s.sessionsMu.Lock()
+	start := s.samplingTurn % len(clients)
+	s.samplingTurn++
+	s.sessionsMu.Unlock()
+
+	rotated := make([]server.SessionWithSampling, 0, len(clients))
+	rotated = append(rotated, clients[start:]...)
+	return append(rotated, clients[:start]...)
+}
+
+// mayReadTopic reports whether the agent of a client session may read a
+// topic, with the identity its requests would have: the session's API
+// token, its registered agent or the default sender.
+func (s *Server) mayReadTopic(session server.ClientSession, topicID int64) (bool, error) {
+	ctx := s.mcpServer.WithContext(context.Background(), session)
+
+	s.sessionsMu.Lock()
+	if state := s.identities[session.SessionID()]; state != nil && state.token != nil {
+		ctx = context.WithValue(ctx, tokenKey{}, state.token)
+	}
+	s.sessionsMu.Unlock()
+
+	return s.db.CheckTopicAccess(topicID, s.getPrincipal(ctx), db.AccessReader)
+}
+
+// samplingWorkerName identifies this serve process in sampling_workers.
+func (s *Server) samplingWorkerName() string {
+	return fmt.Sprintf("%s/%d", s.DefaultSender, os.Getpid())
+}
+
+// startSamplingWorker starts the sampling worker if sampling is enabled and
+// returns a function that stops it.
+func (s *Server) startSamplingWorker() (stop func()) {
+	if s.sampling == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.runSamplingWorker(ctx)
+	}()
+	log.Printf("MCP sampling enabled (policy: %s)", s.sampling.Policy)
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// runSamplingWorker answers queued sampling requests until ctx is done.
+func (s *Server) runSamplingWorker(ctx context.Context) {
+	worker := s.samplingWorkerName()
+	defer func() {
+		if err := s.db.RemoveSamplingWorker(worker); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}()
+
+	ticker := time.NewTicker(samplingPollInterval)
+	defer ticker.Stop()
+
+	var lastHeartbeat time.Time
+	for {
+		if time.Since(lastHeartbeat) >= samplingHeartbeatInterval {
+			if err := s.db.UpdateSamplingWorker(worker, len(s.samplingClients())); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			lastHeartbeat = time.Now()
+		}
+
+		s.processSamplingRequests(ctx, worker)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processSamplingRequests claims and answers pending requests while there
+// are clients to ask. Requests are left for other serve processes otherwise.
+func (s *Server) processSamplingRequests(ctx context.Context, worker string) {
+	for ctx.Err() == nil && len(s.samplingClients()) > 0 {
+		req, err := s.db.ClaimSamplingRequest(worker)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			return
+		}
+		if req == nil {
+			return
+		}
+		s.answerSamplingRequest(ctx, req)
+	}
+}
+
+// answerSamplingRequest asks the allowed clients in policy order until one
+// returns text, and stores the outcome. A prompt about a topic contains its
+// messages, so only clients whose agent may read the topic are asked.
+func (s *Server) answerSamplingRequest(ctx context.Context, req *db.SamplingRequest) {
+	request := mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{{
+				Role:    mcp.RoleUser,
+				Content: mcp.NewTextContent(req.Prompt),
+			}},
+			SystemPrompt:   req.SystemPrompt,
+			IncludeContext: "none",
+			MaxTokens:      req.MaxTokens,
+		},
+	}
+
+	lastErr := fmt.Errorf("no sampling-capable client connected")
+	for _, client := range s.nextSamplingClients() {
+		name := client.SessionID()
+		if info, ok := client.(server.SessionWithClientInfo); ok {
+			name = info.GetClientInfo().Name
+		}
+
+		if req.TopicID != 0 {
+			allowed, err := s.mayReadTopic(client, req.TopicID)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if !allowed {
+				lastErr = fmt.Errorf("%s may not read topic %d", name, req.TopicID)
+				continue
+			}
+		}
+
+		clientCtx, cancel := context.WithTimeout(s.mcpServer.WithContext(ctx, client), s.sampling.Timeout)
+		result, err := client.RequestSampling(clientCtx, request)
+		cancel()
+
+		if err == nil {
+			text := samplingText(result.Content)
+			if text != "" {
+				if err := s.db.CompleteSamplingRequest(int64(req.ID), name, result.Model, text); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+				return
+			}
+			err = fmt.Errorf("empty text in response")
+		}
+
+		log.Printf("Sampling request %d: client %s failed: %v", req.ID, name, err)
+		lastErr = fmt.Errorf("%s: %w", name, err)
+	}
+
+	if _, err := s.db.FailSamplingRequest(int64(req.ID), lastErr.Error()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// samplingText extracts the text of a sampling result. Results decoded from
+// JSON arrive as maps rather than mcp.TextContent.
+func samplingText(content any) string {
+	if text, ok := mcp.AsTextContent(content); ok {
+		return text.Text
+	}
+	if text, ok := content.(*mcp.TextContent); ok && text != nil {
+		return text.Text
+	}
+	if m, ok := content.(map[string]any); ok && m["type"] == "text" {
+		text, _ := m["text"].(string)
+		return text
+	}
+	return ""
+}
@@ -2,9 +2,13 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -27,38 +31,67 @@ type Server struct {
 	db            *db.DB
 	DefaultSender string
 	DefaultRole   string
-	CurrentSender string
 	notifier      *db.Notifier
-}
-
-// getSender returns the current sender, falling back to default if not set.
-func (s *Server) getSender() string {
-	if s.CurrentSender != "" {
-		return s.CurrentSender
-	}
-	if s.DefaultSender != "" {
-		return s.DefaultSender
-	}
-	return "unknown"
+	feed          *db.EventFeed
+
+	// AllowedOrigins lists the origins that may make cross-origin requests
+	// to the HTTP transports ("*" = any). Requests from other origins are
+	// refused.
+	AllowedOrigins []string
+	authRequired   bool // Set by EnableAuth
+
+	// RequireSignatures refuses unsigned posts from agents with a registered
+	// signing key.
+	RequireSignatures bool
+	signer            *messageSigner // Set by EnableSigning (nil = posts are not signed)
+
+	// AdminAgents may edit and delete any agent's messages, like tokens
+	// with the db.RoleAdmin role.
+	AdminAgents []string
+
+	// Redaction is the action of the built-in secret detectors on posts and
+	// direct messages: db.RedactMask (default), db.RedactReject or "" (off).
+	// Rules added with 'agent-hub redact add' always apply.
+	Redaction string
+
+	sessionsMu   sync.Mutex
+	sessions     []server.ClientSession   // Connected clients, in connection order
+	identities   map[string]*sessionState // By transport session ID
+	sampling     *SamplingConfig          // Set by EnableSampling (nil = sampling disabled)
+	samplingTurn int                      // Round-robin position for SamplingPolicyRoundRobin
 }
 
 // NewServer creates a new MCP server with the given database, default sender, and role.
 func NewServer(database *db.DB, defaultSender, defaultRole string) *Server {
-	// Create MCP server with tool and resource capabilities
-	mcpServer := server.NewMCPServer(
-		"agent-hub-mcp",
-		"0.1.0",
-		server.WithToolCapabilities(true),
-		server.WithResourceCapabilities(true, true),
-	)
+	notifier := db.NewNotifier()
 
 	s := &Server{
-		mcpServer:     mcpServer,
 		db:            database,
 		DefaultSender: defaultSender,
 		DefaultRole:   defaultRole,
+		notifier:      notifier,
+		feed:          db.NewEventFeed(database, notifier, db.DefaultFeedInterval),
+		identities:    make(map[string]*sessionState),
+		Redaction:     db.RedactMask,
 	}
 
+	// Create MCP server with tool and resource capabilities; mutating tool
+	// calls are recorded in the audit log
+	hooks := &server.Hooks{}
+	s.mcpServer = server.NewMCPServer(
+		"agent-hub-mcp",
+		"0.1.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(s.auditToolCalls),
+	)
+
+	// Track client sessions for sampling and per-session identity
+	hooks.AddOnRegisterSession(s.addSession)
+	hooks.AddOnUnregisterSession(s.removeSession)
+	hooks.AddAfterInitialize(s.recordClientInfo)
+
 	// Register tools
 	s.registerTools()
 
@@ -85,7 +118,7 @@ func (s *Server) registerTools() {
 	// bbs_post tool
 	postTool := mcp.NewTool(
 		"bbs_post",
-		mcp.WithDescription("Post a message to a topic"),
+		mcp.WithDescription("Post a message to a topic. Secrets such as API keys, tokens and private keys are masked (or the post is refused)"),
 		mcp.WithNumber("topic_id",
 			mcp.Required(),
 			mcp.Description("The ID of the topic"),
@@ -94,6 +127,12 @@ func (s *Server) registerTools() {
 			mcp.Required(),
 			mcp.Description("The message content"),
 		),
+		mcp.WithNumber("reply_to",
+			mcp.Description("ID of a message in the same topic to reply to"),
+		),
+		mcp.WithString("signature",
+			mcp.Description("Base64 ed25519 signature of the message by your registered signing key (default: signed by the server if it holds your key)"),
+		),
 	)
 
 	s.mcpServer.AddTool(postTool, s.handleBBSPost)
@@ -101,7 +140,7 @@ func (s *Server) registerTools() {
 	// bbs_read tool
 	readTool := mcp.NewTool(
 		"bbs_read",
-		mcp.WithDescription("Read recent messages from a topic"),
+		mcp.WithDescription("Read recent messages from a topic (marks them as read). If more unread messages exist than fit in limit, the oldest unread ones are returned instead, so repeated calls page through them. Each message's Verification is verified, invalid or unsigned; EditedAt and EditedBy are set on edited messages, and deleted ones read \""+db.DeletedContent+"\""),
 		mcp.WithNumber("topic_id",
 			mcp.Required(),
 			mcp.Description("The ID of the topic"),
@@ -113,10 +152,105 @@ func (s *Server) registerTools() {
 
 	s.mcpServer.AddTool(readTool, s.handleBBSRead)
 
+	// bbs_read_thread tool
+	readThreadTool := mcp.NewTool(
+		"bbs_read_thread",
+		mcp.WithDescription("Read a message together with its tree of replies"),
+		mcp.WithNumber("message_id",
+			mcp.Required(),
+			mcp.Description("The ID of the message"),
+		),
+		mcp.WithBoolean("whole_thread",
+			mcp.Description("Start from the top-level message of the thread instead of message_id"),
+		),
+	)
+
+	s.mcpServer.AddTool(readThreadTool, s.handleBBSReadThread)
+
+	// bbs_list_topics tool
+	listTopicsTool := mcp.NewTool(
+		"bbs_list_topics",
+		mcp.WithDescription("List topics with message counts, last activity, latest summary and your unread count"),
+		mcp.WithString("title",
+			mcp.Description("Only include topics whose title contains this text (case-insensitive)"),
+		),
+		mcp.WithString("active_since",
+			mcp.Description("Only include topics with messages at or after this time (RFC3339 or 'YYYY-MM-DD HH:MM:SS', UTC)"),
+		),
+		mcp.WithBoolean("has_unread",
+			mcp.Description("Only include topics with unread messages for you"),
+		),
+		mcp.WithNumber("cursor",
+			mcp.Description("Pagination cursor (use next_cursor from the previous page)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of topics to return (default: 20, max: 100)"),
+		),
+	)
+
+	s.mcpServer.AddTool(listTopicsTool, s.handleBBSListTopics)
+
+	// bbs_search tool
+	searchTool := mcp.NewTool(
+		"bbs_search",
+		mcp.WithDescription("Full-text search over BBS messages and topic summaries"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Search terms (all terms must match; append * for prefix match)"),
+		),
+		mcp.WithString("sender",
+			mcp.Description("Only messages from this sender"),
+		),
+		mcp.WithNumber("topic_id",
+			mcp.Description("Only search this topic"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only results at or after this time (RFC3339 or 'YYYY-MM-DD HH:MM:SS', UTC)"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only results at or before this time (RFC3339 or 'YYYY-MM-DD HH:MM:SS', UTC)"),
+		),
+		mcp.WithBoolean("include_summaries",
+			mcp.Description("Also search orchestrator summaries (default: true)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results (default: 20, max: 100)"),
+		),
+	)
+
+	s.mcpServer.AddTool(searchTool, s.handleBBSSearch)
+
+	// bbs_get_summary tool
+	getSummaryTool := mcp.NewTool(
+		"bbs_get_summary",
+		mcp.WithDescription("Get the latest orchestrator summary of a topic with its decisions, action items, open questions and risks"),
+		mcp.WithNumber("topic_id",
+			mcp.Required(),
+			mcp.Description("The ID of the topic"),
+		),
+	)
+
+	s.mcpServer.AddTool(getSummaryTool, s.handleBBSGetSummary)
+
+	// bbs_mark_read tool
+	markReadTool := mcp.NewTool(
+		"bbs_mark_read",
+		mcp.WithDescription("Mark messages in a topic as read"),
+		mcp.WithNumber("topic_id",
+			mcp.Required(),
+			mcp.Description("The ID of the topic"),
+		),
+		mcp.WithNumber("message_id",
+			mcp.Description("Mark messages up to and including this ID as read (default: all messages)"),
+		),
+	)
+
+	s.mcpServer.AddTool(markReadTool, s.handleBBSMarkRead)
+
 	// check_hub_status tool
 	checkHubStatusTool := mcp.NewTool(
 		"check_hub_status",
-		mcp.WithDescription("Check hub status for unread messages and team presence"),
+		mcp.WithDescription("Check hub status for unread messages per topic and team presence"),
 	)
 
 	s.mcpServer.AddTool(checkHubStatusTool, s.handleCheckHubStatus)
@@ -142,7 +276,7 @@ func (s *Server) registerTools() {
 		mcp.WithDescription("Register or update your agent identity in the hub"),
 		mcp.WithString("name",
 			mcp.Required(),
-			mcp.Description("Your agent identifier"),
+			mcp.Description("Your agent identifier (\""+db.OrchestratorAgent+"\" is reserved)"),
 		),
 		mcp.WithString("role",
 			mcp.Required(),
@@ -161,17 +295,80 @@ func (s *Server) registerTools() {
 	// wait_notify tool
 	waitNotifyTool := mcp.NewTool(
 		"wait_notify",
-		mcp.WithDescription("Wait for new messages on a topic (long-polling)"),
+		mcp.WithDescription("Wait for new messages (long-polling) and return every message that arrived since the cursor"),
 		mcp.WithString("agent_id",
-			mcp.Required(),
-			mcp.Description("The agent identifier waiting for notifications"),
+			mcp.Description("Your agent name (default: your registered identity); a different agent's name is refused"),
 		),
 		mcp.WithNumber("timeout_sec",
 			mcp.Description("Timeout in seconds (default: 180)"),
 		),
+		mcp.WithArray("topic_ids",
+			mcp.Description("Only wait for messages in these topics (default: all topics)"),
+			mcp.WithNumberItems(),
+		),
+		mcp.WithNumber("since_message_id",
+			mcp.Description("Return messages newer than this ID; pass last_message_id from the previous call to never miss messages (default: only messages posted after this call)"),
+		),
+		mcp.WithBoolean("mentions_only",
+			mcp.Description("Only wake up for messages that mention you"),
+		),
+		mcp.WithBoolean("include_dms",
+			mcp.Description("Also wake up for direct messages to you (default: true)"),
+		),
+		mcp.WithNumber("since_dm_id",
+			mcp.Description("Return direct messages newer than this ID; pass last_dm_id from the previous call (default: only direct messages sent after this call)"),
+		),
 	)
 
 	s.mcpServer.AddTool(waitNotifyTool, s.handleWaitNotify)
+
+	// bbs_send_dm tool
+	sendDMTool := mcp.NewTool(
+		"bbs_send_dm",
+		mcp.WithDescription("Send a private direct message to another agent"),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("The name of the recipient agent"),
+		),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("The message content"),
+		),
+	)
+
+	s.mcpServer.AddTool(sendDMTool, s.handleBBSSendDM)
+
+	// bbs_read_dms tool
+	readDMsTool := mcp.NewTool(
+		"bbs_read_dms",
+		mcp.WithDescription("Read direct messages you sent or received, newest first (marks received ones as read)"),
+		mcp.WithString("with",
+			mcp.Description("Only the conversation with this agent"),
+		),
+		mcp.WithBoolean("unread_only",
+			mcp.Description("Only unread messages sent to you"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of messages to return (default: 20)"),
+		),
+	)
+
+	s.mcpServer.AddTool(readDMsTool, s.handleBBSReadDMs)
+
+	// task_create, task_claim, task_update and task_list tools
+	s.registerTaskTools()
+
+	// lock_acquire, lock_renew, lock_release and lock_list tools
+	s.registerLockTools()
+
+	// kv_get, kv_set, kv_cas, kv_list and kv_history tools
+	s.registerKVTools()
+
+	// topic_set_acl and topic_get_acl tools
+	s.registerACLTools()
+
+	// bbs_edit and bbs_delete tools
+	s.registerEditTools()
 }
 
 // readGuidelines reads the agent collaboration guidelines from the docs directory.
@@ -204,6 +401,53 @@ func (s *Server) registerResources() {
 		}, nil
 	})
 
+	// Register per-topic summary resources
+	summaryTemplate := mcp.NewResourceTemplate(
+		"hub://topics/{id}/summary",
+		"Topic Summary",
+		mcp.WithTemplateDescription("Latest structured orchestrator summary of a topic"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	s.mcpServer.AddResourceTemplate(summaryTemplate, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		// Template variables arrive as lists of values
+		var id string
+		if values, ok := request.Params.Arguments["id"].([]string); ok && len(values) == 1 {
+			id = values[0]
+		}
+		topicID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid topic ID %q", id)
+		}
+
+		if denied := s.checkTopicAccess(ctx, topicID, db.AccessReader, "read_summary"); denied != nil {
+			return nil, fmt.Errorf("access denied to topic %d", topicID)
+		}
+
+		summary, err := s.db.GetLatestSummary(topicID)
+		if err != nil {
+			return nil, err
+		}
+		if summary == nil {
+			return nil, fmt.Errorf("topic %d has no summary yet", topicID)
+		}
+
+		data, err := json.MarshalIndent(summaryResponse(summary), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal summary: %w", err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		}, nil
+	})
+
+	// Register hub://kv/{namespace} resources
+	s.registerKVResources()
+
 	// Register latest-notification resource
 	latestNotificationResource := mcp.NewResource(
 		"hub://latest-notification",
@@ -228,6 +472,8 @@ func (s *Server) registerResources() {
 // Serve starts the MCP server on stdio.
 func (s *Server) Serve() error {
 	log.Println("Starting MCP server on stdio...")
+	defer s.startSamplingWorker()()
+	defer s.startKVWatcher()()
 	return server.ServeStdio(s.mcpServer)
 }
 
@@ -235,39 +481,44 @@ func (s *Server) Serve() error {
 func (s *Server) NewStreamableHTTPHandler() http.Handler {
 	baseHandler := server.NewStreamableHTTPServer(s.mcpServer)
 
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, MCP-Protocol-Version")
+	return s.httpMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baseHandler.ServeHTTP(w, r)
 
-		// Handle Preflight (OPTIONS) requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+		// The transport forgets terminated sessions without unregistering
+		// them, which would leave them connected in the sessions table
+		if r.Method == http.MethodDelete {
+			if sessionID := r.Header.Get(server.HeaderKeySessionID); sessionID != "" {
+				s.mcpServer.UnregisterSession(r.Context(), sessionID)
+			}
 		}
-
-		baseHandler.ServeHTTP(w, r)
-	})
+	}))
 }
 
-// ServeSSE starts the MCP server on an HTTP endpoint with SSE.
-func (s *Server) ServeSSE(addr string) error {
-	log.Printf("Starting MCP server on SSE http://%s...", addr)
-
+// newHTTPHandler returns the handler serving the SSE transport on /sse and
+// /message and the Streamable HTTP transport on /mcp/.
+func (s *Server) newHTTPHandler() http.Handler {
 	sseServer := server.NewSSEServer(s.mcpServer,
 		server.WithBasePath("/"),
 	)
 
 	mux := http.NewServeMux()
-	mux.Handle("/", sseServer)
+	mux.Handle("/", s.httpMiddleware(sseServer))
 	mux.Handle("/mcp/", s.NewStreamableHTTPHandler())
+	return mux
+}
+
+// ServeSSE starts the MCP server on an HTTP endpoint with SSE.
+func (s *Server) ServeSSE(addr string) error {
+	log.Printf("Starting MCP server on SSE http://%s...", addr)
 
 	srv := &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: s.newHTTPHandler(),
 	}
 
+	defer s.startSamplingWorker()()
+	defer s.startKVWatcher()()
+
 	log.Printf("SSE server listening on %s", addr)
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return err
@@ -1,11 +1,19 @@
 package mcp
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/yklcs/agent-hub-mcp/internal/db"
 )
 
@@ -18,6 +26,7 @@ func TestServeStreamableHTTP(t *testing.T) {
 	defer database.Close()
 
 	srv := NewServer(database, "test-sender", "test-role")
+	srv.AllowedOrigins = []string{"*"}
 
 	mux := http.NewServeMux()
 	handler := srv.NewStreamableHTTPHandler()
@@ -65,3 +74,583 @@ func TestServeStreamableHTTP(t *testing.T) {
 		t.Errorf("expected Access-Control-Allow-Origin: *, got '%s'", allowOrigin)
 	}
 }
+
+// fakeSampler answers sampling requests like an MCP client's model would.
+type fakeSampler struct {
+	reply string // Empty = fail
+	calls int
+}
+
+func (f *fakeSampler) CreateMessage(ctx context.Context, req mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	f.calls++
+	if f.reply == "" {
+		return nil, fmt.Errorf("user declined")
+	}
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(f.reply)},
+		Model:           "fake-model",
+	}, nil
+}
+
+func connectSamplingClient(t *testing.T, srv *Server, name string, sampler client.SamplingHandler) *client.Client {
+	t.Helper()
+	var c *client.Client
+	var err error
+	if sampler != nil {
+		c, err = client.NewInProcessClientWithSamplingHandler(srv.mcpServer, sampler)
+	} else {
+		c, err = client.NewInProcessClient(srv.mcpServer)
+	}
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("failed to start client: %v", err)
+	}
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: name, Version: "1.0"}
+	if _, err := c.Initialize(ctx, init); err != nil {
+		t.Fatalf("failed to initialize client: %v", err)
+	}
+	return c
+}
+
+func TestSamplingWorker(t *testing.T) {
+	database, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv := NewServer(database, "hub", "test")
+	ctx := context.Background()
+
+	declining := &fakeSampler{}
+	willing := &fakeSampler{reply: "sampled summary"}
+	unlisted := &fakeSampler{reply: "should not be asked"}
+	connectSamplingClient(t, srv, "unlisted", unlisted)
+	connectSamplingClient(t, srv, "no-sampling", nil)
+	willingClient := connectSamplingClient(t, srv, "willing", willing)
+	connectSamplingClient(t, srv, "declining", declining)
+
+	// Without the operator's consent nothing is sampled
+	id, _ := database.CreateSamplingRequest(0, "summarize", "system", 100)
+	srv.processSamplingRequests(ctx, "hub/1")
+	if req, _ := database.GetSamplingRequest(id); req.Status != db.SamplingPending {
+		t.Fatalf("expected request to stay pending without consent, got %s", req.Status)
+	}
+
+	if err := srv.EnableSampling(SamplingConfig{Policy: "fastest"}); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+	if err := srv.EnableSampling(SamplingConfig{AllowedClients: []string{"declining", "willing"}}); err != nil {
+		t.Fatalf("EnableSampling failed: %v", err)
+	}
+	if clients := srv.samplingClients(); len(clients) != 2 {
+		t.Fatalf("expected 2 allowed sampling clients, got %d", len(clients))
+	}
+
+	// Clients are tried in preference order until one answers
+	srv.processSamplingRequests(ctx, "hub/1")
+	req, _ := database.GetSamplingRequest(id)
+	if req.Status != db.SamplingDone || req.Result != "sampled summary" || req.Client != "willing" || req.Model != "fake-model" {
+		t.Errorf("unexpected sampling result %+v", req)
+	}
+	if declining.calls != 1 || willing.calls != 1 || unlisted.calls != 0 {
+		t.Errorf("expected declining then willing to be asked, got calls declining=%d willing=%d unlisted=%d",
+			declining.calls, willing.calls, unlisted.calls)
+	}
+
+	// When every allowed client fails the request fails
+	willing.reply = ""
+	id, _ = database.CreateSamplingRequest(0, "summarize again", "system", 100)
+	srv.processSamplingRequests(ctx, "hub/1")
+	if req, _ := database.GetSamplingRequest(id); req.Status != db.SamplingFailed || !strings.Contains(req.Error, "user declined") {
+		t.Errorf("expected failed request, got %+v", req)
+	}
+
+	// Round-robin starts each request at the next client
+	willing.reply = "round robin"
+	declining.calls, willing.calls = 0, 0
+	srv.sampling.Policy = SamplingPolicyRoundRobin
+	for i := 0; i < 2; i++ {
+		database.CreateSamplingRequest(0, "summarize", "system", 100)
+		srv.processSamplingRequests(ctx, "hub/1")
+	}
+	if declining.calls != 1 || willing.calls != 2 {
+		t.Errorf("expected rotation to skip declining once, got declining=%d willing=%d", declining.calls, willing.calls)
+	}
+
+	// Prompts about a restricted topic only go to clients that may read it
+	srv.sampling.Policy = SamplingPolicyFirst
+	declining.calls, willing.calls = 0, 0
+	private, _ := database.CreateTopic("Private")
+	database.SetTopicACL(db.TopicACL{TopicID: private, Owners: []string{"alice"}})
+	id, _ = database.CreateSamplingRequest(private, "summarize private", "system", 100)
+	srv.processSamplingRequests(ctx, "hub/1")
+	if req, _ := database.GetSamplingRequest(id); req.Status != db.SamplingFailed || !strings.Contains(req.Error, "may not read topic") {
+		t.Errorf("expected the request to fail without readers, got %+v", req)
+	}
+	if declining.calls != 0 || willing.calls != 0 {
+		t.Errorf("expected no client to be asked, got declining=%d willing=%d", declining.calls, willing.calls)
+	}
+
+	register := mcp.CallToolRequest{}
+	register.Params.Name = "bbs_register_agent"
+	register.Params.Arguments = map[string]interface{}{"name": "alice", "role": "lead"}
+	if _, err := willingClient.CallTool(ctx, register); err != nil {
+		t.Fatalf("bbs_register_agent failed: %v", err)
+	}
+	id, _ = database.CreateSamplingRequest(private, "summarize private", "system", 100)
+	srv.processSamplingRequests(ctx, "hub/1")
+	if req, _ := database.GetSamplingRequest(id); req.Status != db.SamplingDone || req.Client != "willing" {
+		t.Errorf("expected alice's client to answer, got %+v", req)
+	}
+	if declining.calls != 0 {
+		t.Errorf("expected the client without access not to be asked, got %d calls", declining.calls)
+	}
+}
+
+// fakeSession is a connected client that records the notifications it receives.
+type fakeSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (f *fakeSession) Initialize()       {}
+func (f *fakeSession) Initialized() bool { return true }
+func (f *fakeSession) SessionID() string { return f.id }
+func (f *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return f.notifications
+}
+
+func TestKVResourceNotifications(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shared.db")
+
+	// The writer is a separate process sharing the database file
+	databaseA, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer databaseA.Close()
+	databaseB, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer databaseB.Close()
+
+	srv := NewServer(databaseA, "hub", "test")
+	writer := NewServer(databaseB, "alice", "lead")
+
+	ctx := context.Background()
+	session := &fakeSession{id: "watcher", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if err := srv.mcpServer.RegisterSession(ctx, session); err != nil {
+		t.Fatalf("failed to register session: %v", err)
+	}
+
+	stop := srv.startKVWatcher()
+	defer stop()
+	time.Sleep(50 * time.Millisecond)
+
+	writer.handleKVSet(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{
+		"namespace": "repo", "key": "branch", "value": "main",
+	}}})
+
+	select {
+	case n := <-session.notifications:
+		if n.Method != mcp.MethodNotificationResourceUpdated || n.Params.AdditionalFields["uri"] != "hub://kv/repo" {
+			t.Errorf("expected an update for hub://kv/repo, got %+v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a resource update notification")
+	}
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "hub://kv/repo"}}`
+	data, _ := json.Marshal(srv.mcpServer.HandleMessage(ctx, []byte(request)))
+	if !strings.Contains(string(data), `\"Value\": \"main\"`) || !strings.Contains(string(data), `\"UpdatedBy\": \"alice\"`) {
+		t.Errorf("unexpected resource contents: %s", data)
+	}
+}
+
+// startHTTPClient initializes a client of a server's HTTP transports.
+func startHTTPClient(t *testing.T, c *client.Client, name string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("failed to start client %s: %v", name, err)
+	}
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: name, Version: "1.0"}
+	if _, err := c.Initialize(ctx, init); err != nil {
+		t.Fatalf("failed to initialize client %s: %v", name, err)
+	}
+}
+
+func TestPerSessionIdentity(t *testing.T) {
+	database, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv := NewServer(database, "hub", "test")
+	ts := httptest.NewServer(srv.newHTTPHandler())
+	defer ts.Close()
+
+	topicID, err := database.CreateTopic("Identity")
+	if err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+
+	alice, err := client.NewSSEMCPClient(ts.URL + "/sse")
+	if err != nil {
+		t.Fatalf("failed to create SSE client: %v", err)
+	}
+	bob, err := client.NewStreamableHttpClient(ts.URL + "/mcp/")
+	if err != nil {
+		t.Fatalf("failed to create Streamable HTTP client: %v", err)
+	}
+	clients := map[string]*client.Client{"alice": alice, "bob": bob}
+
+	call := func(c *client.Client, name string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		return c.CallTool(context.Background(), req)
+	}
+
+	// Both agents register, then post concurrently
+	const posts = 10
+	errs := make(chan error, len(clients))
+	for name, c := range clients {
+		startHTTPClient(t, c, name+"-client")
+		go func(name string, c *client.Client) {
+			if _, err := call(c, "bbs_register_agent", map[string]interface{}{"name": name, "role": "coder"}); err != nil {
+				errs <- err
+				return
+			}
+			for i := 0; i < posts; i++ {
+				result, err := call(c, "bbs_post", map[string]interface{}{"topic_id": topicID, "content": fmt.Sprintf("%s %d", name, i)})
+				if err == nil && result.IsError {
+					err = fmt.Errorf("bbs_post failed for %s", name)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}(name, c)
+	}
+	for range clients {
+		if err := <-errs; err != nil {
+			t.Fatalf("client failed: %v", err)
+		}
+	}
+
+	messages, err := database.GetMessages(topicID, 2*posts)
+	if err != nil {
+		t.Fatalf("failed to get messages: %v", err)
+	}
+	if len(messages) != 2*posts {
+		t.Fatalf("expected %d messages, got %d", 2*posts, len(messages))
+	}
+	for _, m := range messages {
+		if !strings.HasPrefix(m.Content, m.Sender+" ") {
+			t.Errorf("message %q was posted as %s", m.Content, m.Sender)
+		}
+	}
+
+	// Each session is recorded with its client and agent
+	sessions, err := database.ListSessions(true, 0)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 connected sessions, got %+v", sessions)
+	}
+	for _, session := range sessions {
+		if session.ClientName != session.Agent+"-client" || session.Role != "coder" || session.ConnectedAt == "" {
+			t.Errorf("unexpected session %+v", session)
+		}
+	}
+
+	// Disconnecting closes the sessions
+	alice.Close()
+	bob.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		connected, err := database.ListSessions(true, 0)
+		if err != nil {
+			t.Fatalf("ListSessions failed: %v", err)
+		}
+		if len(connected) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected sessions to disconnect, still connected: %+v", connected)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	all, _ := database.ListSessions(false, 0)
+	for _, session := range all {
+		if session.DisconnectedAt == "" {
+			t.Errorf("expected disconnect time for %+v", session)
+		}
+	}
+}
+
+func TestHTTPAuth(t *testing.T) {
+	database, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv := NewServer(database, "hub", "test")
+	srv.EnableAuth()
+	srv.AllowedOrigins = []string{"https://dashboard.example"}
+	ts := httptest.NewServer(srv.newHTTPHandler())
+	defer ts.Close()
+
+	aliceToken, _, err := database.CreateToken("alice", "reviewer", "")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	bobToken, bob, err := database.CreateToken("bob", "coder", "")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	topicID, _ := database.CreateTopic("Auth")
+
+	status := func(method, path, token, origin string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader("{}"))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Every transport endpoint needs a valid token
+	for _, path := range []string{"/sse", "/message", "/mcp/"} {
+		if code := status("POST", path, "", ""); code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for %s without a token, got %d", path, code)
+		}
+		if code := status("POST", path, db.TokenPrefix+"forged", ""); code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for %s with an unknown token, got %d", path, code)
+		}
+	}
+
+	// Only allowlisted origins may make cross-origin requests
+	if code := status("OPTIONS", "/mcp/", "", "https://evil.example"); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a disallowed origin, got %d", code)
+	}
+	req, _ := http.NewRequest("OPTIONS", ts.URL+"/mcp/", nil)
+	req.Header.Set("Origin", "https://dashboard.example")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("preflight failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "https://dashboard.example" ||
+		!strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Errorf("unexpected preflight response %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// The sender comes from the token, not from registration
+	c, err := client.NewStreamableHttpClient(ts.URL+"/mcp/",
+		transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer " + bobToken}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer c.Close()
+	startHTTPClient(t, c, "bob-client")
+
+	call := func(name string, args map[string]interface{}) (*mcp.CallToolResult, string) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		result, err := c.CallTool(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		text, _ := mcp.AsTextContent(result.Content[0])
+		return result, text.Text
+	}
+
+	if result, text := call("bbs_register_agent", map[string]interface{}{"name": "alice", "role": "admin"}); !result.IsError || !strings.Contains(text, `bound to agent "bob"`) {
+		t.Errorf("expected registering as another agent to fail, got %s", text)
+	}
+	if result, text := call("bbs_register_agent", map[string]interface{}{"name": "bob", "role": "admin"}); result.IsError {
+		t.Errorf("expected registration as bob to succeed, got %s", text)
+	}
+	if presence, _ := database.GetAgentPresence("bob"); presence == nil || presence.Role != "coder" {
+		t.Errorf("expected bob's role to come from the token, got %+v", presence)
+	}
+	call("bbs_post", map[string]interface{}{"topic_id": topicID, "content": "hello"})
+	messages, _ := database.GetMessages(topicID, 10)
+	if len(messages) != 1 || messages[0].Sender != "bob" {
+		t.Errorf("expected one message from bob, got %+v", messages)
+	}
+
+	// wait_notify also follows the token: bob can't wait as alice
+	database.SendDirectMessage("carol", "alice", "for alice only")
+	if result, text := call("wait_notify", map[string]interface{}{"agent_id": "alice", "since_dm_id": 0, "timeout_sec": 1}); !result.IsError || strings.Contains(text, "for alice only") {
+		t.Errorf("expected waiting as alice with bob's token to be refused, got %s", text)
+	}
+	if result, text := call("wait_notify", map[string]interface{}{"since_dm_id": 0, "timeout_sec": 1}); result.IsError || strings.Contains(text, "for alice only") {
+		t.Errorf("expected bob to wait as himself, got %s", text)
+	}
+	if unread, _ := database.CountUnreadDirectMessages("alice"); unread != 1 {
+		t.Errorf("expected alice's DM to stay unread, got %d unread", unread)
+	}
+
+	// Another token can't use bob's session
+	sessionID := c.GetSessionId()
+	req, _ = http.NewRequest("POST", ts.URL+"/mcp/", strings.NewReader(`{"jsonrpc":"2.0","id":9,"method":"ping"}`))
+	req.Header.Set("Authorization", "Bearer "+aliceToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for another token's session, got %d", resp.StatusCode)
+	}
+
+	// Revoked tokens are refused
+	if err := database.RevokeToken(bob.ID); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if code := status("POST", "/mcp/", bobToken, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a revoked token, got %d", code)
+	}
+}
+
+func TestAuditToolCalls(t *testing.T) {
+	database, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	srv := NewServer(database, "hub", "test")
+	ts := httptest.NewServer(srv.newHTTPHandler())
+	defer ts.Close()
+
+	c, err := client.NewStreamableHttpClient(ts.URL + "/mcp/")
+	if err != nil {
+		t.Fatalf("failed to create Streamable HTTP client: %v", err)
+	}
+	defer c.Close()
+	startHTTPClient(t, c, "auditor")
+
+	call := func(name string, args map[string]interface{}) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		if _, err := c.CallTool(context.Background(), req); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+	}
+
+	call("bbs_register_agent", map[string]interface{}{"name": "alice", "role": "coder"})
+	call("bbs_create_topic", map[string]interface{}{"title": "Audited"})
+	postArgs := map[string]interface{}{"topic_id": float64(1), "content": "hello"}
+	call("bbs_post", postArgs)
+	call("bbs_read", map[string]interface{}{"topic_id": float64(1)})
+	call("check_hub_status", nil)
+	call("bbs_post", map[string]interface{}{"topic_id": float64(1), "content": "lost", "reply_to": float64(99)})
+
+	events, err := database.ListAuditEvents(db.AuditQuery{})
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	var actions []string
+	for _, e := range events {
+		actions = append(actions, e.Actor+":"+e.Action+":"+e.Result)
+	}
+	want := "alice:bbs_post:error alice:bbs_post:ok alice:bbs_create_topic:ok hub:bbs_register_agent:ok"
+	if got := strings.Join(actions, " "); got != want {
+		t.Fatalf("unexpected audit events:\n got: %s\nwant: %s", got, want)
+	}
+
+	if events[1].ArgsHash != db.HashArgs(postArgs) || events[1].Session == "" || events[1].Source != db.AuditSourceMCP {
+		t.Errorf("expected the post's argument hash and session, got %+v", events[1])
+	}
+	if events[0].Detail == "" {
+		t.Error("expected the failed post's error to be recorded")
+	}
+}
+
+func TestHTTPPostsAreNotServerSigned(t *testing.T) {
+	database, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	encoded, _ := db.GenerateSigningKey()
+	key, _ := db.ParseSigningKey(encoded)
+	database.RegisterSigningKey("hub", db.PublicKeyString(key))
+	topicID, _ := database.CreateTopic("Signing")
+
+	post := func(srv *Server, headers map[string]string, content string) {
+		t.Helper()
+		ts := httptest.NewServer(srv.newHTTPHandler())
+		defer ts.Close()
+		c, err := client.NewSSEMCPClient(ts.URL+"/sse", transport.WithHeaders(headers))
+		if err != nil {
+			t.Fatalf("failed to create SSE client: %v", err)
+		}
+		defer c.Close()
+		startHTTPClient(t, c, "poster")
+
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "bbs_post"
+		req.Params.Arguments = map[string]interface{}{"topic_id": topicID, "content": content}
+		if result, err := c.CallTool(context.Background(), req); err != nil || result.IsError {
+			t.Fatalf("bbs_post failed: %v %+v", err, result)
+		}
+	}
+
+	// An unauthenticated session posting as the signing agent isn't signed
+	open := NewServer(database, "hub", "test")
+	open.EnableSigning("hub", key)
+	post(open, nil, "anyone can send this")
+
+	// A token bound to the signing agent is
+	authed := NewServer(database, "hub", "test")
+	authed.EnableAuth()
+	authed.EnableSigning("hub", key)
+	token, _, _ := database.CreateToken("hub", "test", "")
+	post(authed, map[string]string{"Authorization": "Bearer " + token}, "the hub itself")
+
+	messages, _ := database.GetMessages(topicID, 10)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %+v", messages)
+	}
+	if messages[1].Sender != "hub" || messages[1].Verification != db.VerificationUnsigned {
+		t.Errorf("expected the unauthenticated post to be unsigned, got %+v", messages[1])
+	}
+	if messages[0].Verification != db.VerificationVerified {
+		t.Errorf("expected the token's post to be signed, got %+v", messages[0])
+	}
+}
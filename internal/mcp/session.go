@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// sessionState is what the server knows about one client session.
+type sessionState struct {
+	rowID int64        // Row in the sessions table (0 = not recorded)
+	token *db.APIToken // API token that opened the session (nil = none)
+	agent string       // Name registered with bbs_register_agent
+	role  string
+}
+
+// sessionKey returns the transport session ID of the client making a
+// request. Requests made outside any session (e.g. direct handler calls)
+// share the empty key.
+func sessionKey(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// addSession tracks a connected client session.
+func (s *Server) addSession(ctx context.Context, session server.ClientSession) {
+	rowID, err := s.db.OpenSession(session.SessionID())
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Streamable HTTP sessions are registered after initialize
+	if info, ok := session.(server.SessionWithClientInfo); ok && rowID != 0 {
+		if client := info.GetClientInfo(); client.Name != "" {
+			if err := s.db.SetSessionClient(rowID, client.Name, client.Version); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	s.sessions = append(s.sessions, session)
+	state := &sessionState{rowID: rowID}
+	if token := tokenFromContext(ctx); token != nil {
+		state.token = token
+	}
+	s.identities[session.SessionID()] = state
+}
+
+// sessionUsableBy reports whether a request authenticated with token may
+// use a session, i.e. the session was not opened with another token.
+func (s *Server) sessionUsableBy(sessionID string, token *db.APIToken) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	state := s.identities[sessionID]
+	return state == nil || state.token == nil || state.token.ID == token.ID
+}
+
+// removeSession stops tracking a disconnected client session.
+func (s *Server) removeSession(ctx context.Context, session server.ClientSession) {
+	s.sessionsMu.Lock()
+	state := s.identities[session.SessionID()]
+	delete(s.identities, session.SessionID())
+	for i, tracked := range s.sessions {
+		if tracked.SessionID() == session.SessionID() {
+			s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+			break
+		}
+	}
+	s.sessionsMu.Unlock()
+
+	if state != nil && state.rowID != 0 {
+		if err := s.db.CloseSession(state.rowID); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// recordClientInfo stores the client name and version a session sent in
+// its initialize request.
+func (s *Server) recordClientInfo(ctx context.Context, id any, req *mcp.InitializeRequest, result *mcp.InitializeResult) {
+	s.sessionsMu.Lock()
+	state := s.identities[sessionKey(ctx)]
+	s.sessionsMu.Unlock()
+
+	if state == nil || state.rowID == 0 {
+		return
+	}
+	if err := s.db.SetSessionClient(state.rowID, req.Params.ClientInfo.Name, req.Params.ClientInfo.Version); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// setIdentity binds an agent name and role to the session making the
+// request.
+func (s *Server) setIdentity(ctx context.Context, agent, role string) {
+	key := sessionKey(ctx)
+
+	s.sessionsMu.Lock()
+	state := s.identities[key]
+	if state == nil {
+		state = &sessionState{}
+		s.identities[key] = state
+	}
+	state.agent = agent
+	state.role = role
+	rowID := state.rowID
+	s.sessionsMu.Unlock()
+
+	if rowID != 0 {
+		if err := s.db.SetSessionAgent(rowID, agent, role); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// getSender returns the agent the request's API token is bound to, or else
+// the agent registered by the session making the request, falling back to
+// the default sender if it hasn't registered.
+func (s *Server) getSender(ctx context.Context) string {
+	if token := tokenFromContext(ctx); token != nil {
+		return token.Agent
+	}
+
+	s.sessionsMu.Lock()
+	state := s.identities[sessionKey(ctx)]
+	s.sessionsMu.Unlock()
+
+	if state != nil && state.agent != "" {
+		return state.agent
+	}
+	if s.DefaultSender != "" {
+		return s.DefaultSender
+	}
+	return "unknown"
+}
+
+// getRole returns the role matching getSender: the API token's role, the
+// role registered by the session, or the default role.
+func (s *Server) getRole(ctx context.Context) string {
+	if token := tokenFromContext(ctx); token != nil {
+		return token.Role
+	}
+
+	s.sessionsMu.Lock()
+	state := s.identities[sessionKey(ctx)]
+	s.sessionsMu.Unlock()
+
+	if state != nil && state.agent != "" {
+		return state.role
+	}
+	return s.DefaultRole
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// messageSigner signs the posts of the agent whose key the server holds.
+type messageSigner struct {
+	agent string
+	key   ed25519.PrivateKey
+}
+
+// EnableSigning makes the server sign posts by agent with key when the
+// client didn't sign them itself. The key's public half must be registered
+// for agent (see 'agent-hub key generate'). Only stdio requests and HTTP
+// requests whose API token is bound to agent are signed, since anyone else
+// reaching an HTTP transport could post under that name.
+func (s *Server) EnableSigning(agent string, key ed25519.PrivateKey) {
+	s.signer = &messageSigner{agent: agent, key: key}
+}
+
+// signPost returns the signature to post a message with: the client's own,
+// or one made with the server's key for its agent. It refuses unsigned posts
+// from signing agents if RequireSignatures is set.
+func (s *Server) signPost(ctx context.Context, topicID int64, sender, content string, replyTo int64, signature string) (string, error) {
+	if signature == "" && s.signer != nil && s.signer.agent == sender && s.mayUseSigner(ctx) {
+		signature = db.SignMessage(s.signer.key, topicID, sender, content, replyTo)
+	}
+	if signature != "" || !s.RequireSignatures {
+		return signature, nil
+	}
+
+	signing, err := s.db.IsSigningAgent(sender)
+	if err != nil {
+		return "", err
+	}
+	if signing {
+		return "", fmt.Errorf("%s has a registered signing key, and this hub refuses unsigned posts from signing agents; pass a signature", sender)
+	}
+	return "", nil
+}
+
+// mayUseSigner reports whether the server's key may sign the request's
+// posts: stdio requests come from whoever runs the server with the key,
+// while HTTP requests must authenticate with a token bound to its agent.
+func (s *Server) mayUseSigner(ctx context.Context) bool {
+	if token := tokenFromContext(ctx); token != nil {
+		return token.Agent == s.signer.agent
+	}
+	return !fromHTTP(ctx)
+}
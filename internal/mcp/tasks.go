@@ -0,0 +1,279 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
+)
+
+// registerTaskTools registers the task board tools.
+func (s *Server) registerTaskTools() {
+	statuses := strings.Join(db.TaskStatuses, ", ")
+	priorities := strings.Join(db.TaskPriorities, ", ")
+
+	// task_create tool
+	createTool := mcp.NewTool(
+		"task_create",
+		mcp.WithDescription("Add a task to the shared task board"),
+		mcp.WithString("title",
+			mcp.Required(),
+			mcp.Description("Short description of the work"),
+		),
+		mcp.WithString("description",
+			mcp.Description("Details, acceptance criteria or links"),
+		),
+		mcp.WithNumber("topic_id",
+			mcp.Description("Topic where the task is discussed"),
+		),
+		mcp.WithString("assignee",
+			mcp.Description("Agent to assign the task to (default: unassigned)"),
+		),
+		mcp.WithString("priority",
+			mcp.Description("Priority: "+priorities+" (default: normal)"),
+		),
+		mcp.WithArray("blocked_by",
+			mcp.Description("IDs of tasks that must be done first"),
+			mcp.WithNumberItems(),
+		),
+	)
+
+	s.mcpServer.AddTool(createTool, s.handleTaskCreate)
+
+	// task_claim tool
+	claimTool := mcp.NewTool(
+		"task_claim",
+		mcp.WithDescription("Assign an unclaimed, unblocked task to yourself and mark it in_progress"),
+		mcp.WithNumber("task_id",
+			mcp.Required(),
+			mcp.Description("The ID of the task"),
+		),
+	)
+
+	s.mcpServer.AddTool(claimTool, s.handleTaskClaim)
+
+	// task_update tool
+	updateTool := mcp.NewTool(
+		"task_update",
+		mcp.WithDescription("Change a task's status, assignee, priority, blockers or text; omitted fields are kept"),
+		mcp.WithNumber("task_id",
+			mcp.Required(),
+			mcp.Description("The ID of the task"),
+		),
+		mcp.WithString("status",
+			mcp.Description("New status: "+statuses),
+		),
+		mcp.WithString("assignee",
+			mcp.Description("New assignee (empty string to unassign)"),
+		),
+		mcp.WithString("priority",
+			mcp.Description("New priority: "+priorities),
+		),
+		mcp.WithArray("blocked_by",
+			mcp.Description("Replace the IDs of tasks that must be done first (empty list to clear)"),
+			mcp.WithNumberItems(),
+		),
+		mcp.WithString("title",
+			mcp.Description("New title"),
+		),
+		mcp.WithString("description",
+			mcp.Description("New description"),
+		),
+	)
+
+	s.mcpServer.AddTool(updateTool, s.handleTaskUpdate)
+
+	// task_list tool
+	listTool := mcp.NewTool(
+		"task_list",
+		mcp.WithDescription("List tasks on the board, most urgent first"),
+		mcp.WithString("status",
+			mcp.Description("Comma-separated statuses to include: "+statuses+" (default: all but done and cancelled)"),
+		),
+		mcp.WithString("assignee",
+			mcp.Description("Only tasks assigned to this agent"),
+		),
+		mcp.WithBoolean("mine",
+			mcp.Description("Only tasks assigned to you"),
+		),
+		mcp.WithNumber("topic_id",
+			mcp.Description("Only tasks in this topic"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of tasks to return (default: 100)"),
+		),
+	)
+
+	s.mcpServer.AddTool(listTool, s.handleTaskList)
+}
+
+// handleTaskCreate handles the task_create tool.
+func (s *Server) handleTaskCreate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	title, err := req.RequireString("title")
+	if err != nil {
+		return mcp.NewToolResultError("title is required and must be a string"), nil
+	}
+
+	task := db.Task{
+		Title:       title,
+		Description: req.GetString("description", ""),
+		TopicID:     int(req.GetFloat("topic_id", 0)),
+		Assignee:    req.GetString("assignee", ""),
+		Priority:    req.GetString("priority", ""),
+		BlockedBy:   taskIDs(req.GetIntSlice("blocked_by", nil)),
+		CreatedBy:   s.getSender(ctx),
+	}
+	if task.TopicID != 0 {
+		if denied := s.checkTopicAccess(ctx, int64(task.TopicID), db.AccessWriter, "create_task"); denied != nil {
+			return denied, nil
+		}
+	}
+
+	id, err := s.db.CreateTask(task)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create task: %v", err)), nil
+	}
+
+	return s.taskResult(id)
+}
+
+// handleTaskClaim handles the task_claim tool.
+func (s *Server) handleTaskClaim(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	taskID, err := req.RequireFloat("task_id")
+	if err != nil {
+		return mcp.NewToolResultError("task_id is required and must be a number"), nil
+	}
+
+	if denied := s.checkTaskAccess(ctx, int64(taskID), "claim_task"); denied != nil {
+		return denied, nil
+	}
+
+	task, err := s.db.ClaimTask(int64(taskID), s.getSender(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to claim task: %v", err)), nil
+	}
+
+	return marshalTask(task)
+}
+
+// handleTaskUpdate handles the task_update tool.
+func (s *Server) handleTaskUpdate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	taskID, err := req.RequireFloat("task_id")
+	if err != nil {
+		return mcp.NewToolResultError("task_id is required and must be a number"), nil
+	}
+
+	if denied := s.checkTaskAccess(ctx, int64(taskID), "update_task"); denied != nil {
+		return denied, nil
+	}
+
+	// Only arguments that were passed are changed
+	args := req.GetArguments()
+	optional := func(name string) *string {
+		if _, ok := args[name]; !ok {
+			return nil
+		}
+		value := req.GetString(name, "")
+		return &value
+	}
+
+	update := db.TaskUpdate{
+		Title:       optional("title"),
+		Description: optional("description"),
+		Assignee:    optional("assignee"),
+		Status:      optional("status"),
+		Priority:    optional("priority"),
+	}
+	if _, ok := args["blocked_by"]; ok {
+		blockers := taskIDs(req.GetIntSlice("blocked_by", nil))
+		update.BlockedBy = &blockers
+	}
+
+	task, err := s.db.UpdateTask(int64(taskID), update)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update task: %v", err)), nil
+	}
+
+	return marshalTask(task)
+}
+
+// handleTaskList handles the task_list tool.
+func (s *Server) handleTaskList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := db.TaskQuery{
+		Assignee: req.GetString("assignee", ""),
+		TopicID:  int64(req.GetFloat("topic_id", 0)),
+		Limit:    int(req.GetFloat("limit", 100)),
+	}
+	if req.GetBool("mine", false) {
+		query.Assignee = s.getSender(ctx)
+	}
+	reader := s.getPrincipal(ctx)
+	query.Reader = &reader
+
+	if status := req.GetString("status", ""); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			query.Statuses = append(query.Statuses, strings.TrimSpace(s))
+		}
+	} else {
+		query.Statuses = []string{db.TaskDraft, db.TaskTodo, db.TaskInProgress}
+	}
+
+	tasks, err := s.db.ListTasks(query)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list tasks: %v", err)), nil
+	}
+
+	if tasks == nil {
+		tasks = []db.Task{}
+	}
+
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal tasks: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// checkTaskAccess returns an error result if the task doesn't exist or the
+// caller may not post to its topic. Tasks without a topic are open to all.
+func (s *Server) checkTaskAccess(ctx context.Context, taskID int64, operation string) *mcp.CallToolResult {
+	task, err := s.db.GetTask(taskID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get task: %v", err))
+	}
+	if task.TopicID == 0 {
+		return nil
+	}
+	return s.checkTopicAccess(ctx, int64(task.TopicID), db.AccessWriter, operation)
+}
+
+// taskResult returns the task with the given ID as a tool result.
+func (s *Server) taskResult(id int64) (*mcp.CallToolResult, error) {
+	task, err := s.db.GetTask(id)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get task: %v", err)), nil
+	}
+	return marshalTask(task)
+}
+
+// marshalTask returns a task as a JSON tool result.
+func marshalTask(task *db.Task) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(task, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal task: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// taskIDs converts tool arguments to task IDs.
+func taskIDs(ids []int) []int64 {
+	result := make([]int64, len(ids))
+	for i, id := range ids {
+		result[i] = int64(id)
+	}
+	return result
+}
@@ -1,7 +1,9 @@
 package ui
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -28,6 +30,21 @@ type Model struct {
 	Height             int
 	TopicSelectorIdx    int
 	PostField          int // 0: sender, 1: content
+	SearchInput        textinput.Model
+	SearchResults      []db.SearchResult
+	SearchIdx          int
+	SearchedQuery      string
+	DMs                []db.DirectMessage
+	DMIdx              int
+	DMInput            textinput.Model
+	DMReplying         bool
+	UnreadDMs          int64
+	Tasks              []db.Task
+	Locks              []db.Lock
+	// Redaction is the action of the built-in secret detectors on posts and
+	// DMs, like serve -redact: db.RedactMask (default), db.RedactReject or ""
+	// (off). Rules added with 'agent-hub redact add' always apply.
+	Redaction string
 }
 
 // InputMode represents the current input mode.
@@ -37,6 +54,9 @@ const (
 	ModeBrowse InputMode = iota
 	ModePost
 	ModeTopicSelect
+	ModeSearch
+	ModeDMs
+	ModeTasks
 )
 
 // FocusPane represents which pane has focus.
@@ -76,6 +96,39 @@ type PresenceLoadedMsg struct {
 	Error     error
 }
 
+// LocksLoadedMsg is sent when held locks are loaded.
+type LocksLoadedMsg struct {
+	Locks []db.Lock
+	Error error
+}
+
+// SearchResultsMsg is sent when a search completes.
+type SearchResultsMsg struct {
+	Query   string
+	Results []db.SearchResult
+	Error   error
+}
+
+// DMsLoadedMsg is sent when the operator's direct messages are loaded.
+type DMsLoadedMsg struct {
+	Messages []db.DirectMessage
+	Error    error
+}
+
+// UnreadDMsMsg is sent with the operator's unread direct message count.
+type UnreadDMsMsg int64
+
+// DMSentMsg is sent when a direct message is sent.
+type DMSentMsg struct {
+	Error error
+}
+
+// TasksLoadedMsg is sent when the task board is loaded.
+type TasksLoadedMsg struct {
+	Tasks []db.Task
+	Error error
+}
+
 // SelectTopicMsg is sent to select a topic.
 type SelectTopicMsg int
 
@@ -97,6 +150,16 @@ func NewModel(database *db.DB) Model {
 	}
 	senderInput.SetValue(defaultSender)
 
+	searchInput := textinput.New()
+	searchInput.Placeholder = "Search messages..."
+	searchInput.CharLimit = 200
+	searchInput.Width = 40
+
+	dmInput := textinput.New()
+	dmInput.Placeholder = "Reply..."
+	dmInput.CharLimit = 1000
+	dmInput.Width = 60
+
 	return Model{
 		db:                 database,
 		Topics:             []db.Topic{},
@@ -112,6 +175,9 @@ func NewModel(database *db.DB) Model {
 		Height:             24,
 		TopicSelectorIdx:   0,
 		PostField:          1, // Start with content field
+		SearchInput:        searchInput,
+		DMInput:            dmInput,
+		Redaction:          db.RedactMask,
 	}
 }
 
@@ -119,6 +185,8 @@ func NewModel(database *db.DB) Model {
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.loadTopicsCmd(),
+		m.loadPresenceCmd(),
+		m.loadLocksCmd(),
 		m.tickCmd(),
 	)
 }
@@ -171,6 +239,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Presences = msg.Presences
 		return m, nil
 
+	case LocksLoadedMsg:
+		if msg.Error != nil {
+			return m, nil
+		}
+		m.Locks = msg.Locks
+		return m, nil
+
 	case TopicSelectedMsg:
 		m.SelectedTopic = msg.Topic
 		m.Messages = msg.Messages
@@ -180,13 +255,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case SelectTopicMsg:
 		return m, m.selectTopicCmd(int(msg))
 
+	case SearchResultsMsg:
+		if msg.Error != nil {
+			m.SearchResults = nil
+			m.SearchedQuery = ""
+			return m, nil
+		}
+		m.SearchResults = msg.Results
+		m.SearchedQuery = msg.Query
+		m.SearchIdx = 0
+		return m, nil
+
+	case DMsLoadedMsg:
+		if msg.Error != nil {
+			return m, nil
+		}
+		m.DMs = msg.Messages
+		if m.DMIdx >= len(m.DMs) {
+			m.DMIdx = 0
+		}
+		m.UnreadDMs = 0
+		return m, nil
+
+	case UnreadDMsMsg:
+		m.UnreadDMs = int64(msg)
+		return m, nil
+
+	case DMSentMsg:
+		if msg.Error != nil {
+			m.ErrorMessage = msg.Error.Error()
+			return m, nil
+		}
+		return m, m.loadDMsCmd()
+
+	case TasksLoadedMsg:
+		if msg.Error != nil {
+			return m, nil
+		}
+		m.Tasks = msg.Tasks
+		return m, nil
+
 	case TickMsg:
-		return m, tea.Batch(
+		cmds := []tea.Cmd{
 			m.loadTopicsCmd(),
 			m.loadMessagesCmd(),
 			m.loadPresenceCmd(),
+			m.loadLocksCmd(),
+			m.loadUnreadDMsCmd(),
 			m.tickCmd(),
-		)
+		}
+		if m.InputMode == ModeDMs {
+			cmds = append(cmds, m.loadDMsCmd())
+		}
+		if m.InputMode == ModeTasks {
+			cmds = append(cmds, m.loadTasksCmd())
+		}
+		return m, tea.Batch(cmds...)
 
 	case MessagePostedMsg:
 		if msg.Error != nil {
@@ -258,6 +382,105 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Search mode
+	if m.InputMode == ModeSearch {
+		switch msg.String() {
+		case "esc":
+			m.InputMode = ModeBrowse
+			m.SearchInput.Blur()
+			return m, nil
+		case "enter":
+			query := m.SearchInput.Value()
+			if query != m.SearchedQuery {
+				return m, m.searchCmd(query)
+			}
+			// Query unchanged: jump to the topic of the highlighted result
+			if m.SearchIdx < len(m.SearchResults) {
+				m.InputMode = ModeBrowse
+				m.SearchInput.Blur()
+				return m, m.selectTopicCmd(m.SearchResults[m.SearchIdx].TopicID)
+			}
+			return m, nil
+		case "up":
+			if m.SearchIdx > 0 {
+				m.SearchIdx--
+			}
+			return m, nil
+		case "down":
+			if m.SearchIdx < len(m.SearchResults)-1 {
+				m.SearchIdx++
+			}
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.SearchInput, cmd = m.SearchInput.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// DM inbox mode
+	if m.InputMode == ModeDMs {
+		if m.DMReplying {
+			switch msg.String() {
+			case "esc":
+				m.DMReplying = false
+				m.DMInput.Reset()
+				m.DMInput.Blur()
+				return m, nil
+			case "enter":
+				if m.DMInput.Value() == "" || m.DMIdx >= len(m.DMs) {
+					return m, nil
+				}
+				to := m.dmCounterpart(m.DMs[m.DMIdx])
+				content := m.DMInput.Value()
+				m.DMReplying = false
+				m.DMInput.Reset()
+				m.DMInput.Blur()
+				return m, m.sendDMCmd(to, content)
+			default:
+				var cmd tea.Cmd
+				m.DMInput, cmd = m.DMInput.Update(msg)
+				return m, cmd
+			}
+		}
+
+		switch msg.String() {
+		case "esc", "d":
+			m.InputMode = ModeBrowse
+			return m, nil
+		case "enter":
+			// Reply to the other party of the highlighted message
+			if m.DMIdx < len(m.DMs) {
+				m.DMReplying = true
+				m.DMInput.Focus()
+				return m, textinput.Blink
+			}
+			return m, nil
+		case "up", "k":
+			if m.DMIdx > 0 {
+				m.DMIdx--
+			}
+			return m, nil
+		case "down", "j":
+			if m.DMIdx < len(m.DMs)-1 {
+				m.DMIdx++
+			}
+			return m, nil
+		}
+		return m, nil
+	}
+
+	// Task board mode
+	if m.InputMode == ModeTasks {
+		switch msg.String() {
+		case "esc", "b":
+			m.InputMode = ModeBrowse
+		case "r":
+			return m, m.loadTasksCmd()
+		}
+		return m, nil
+	}
+
 	// Browse mode key handling
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -295,6 +518,23 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.TopicSelectorIdx = 0
 		return m, nil
 
+	case "/":
+		// Enter search mode
+		m.InputMode = ModeSearch
+		m.SearchInput.Focus()
+		return m, textinput.Blink
+
+	case "d":
+		// Open the direct message inbox
+		m.InputMode = ModeDMs
+		m.DMIdx = 0
+		return m, m.loadDMsCmd()
+
+	case "b":
+		// Open task board
+		m.InputMode = ModeTasks
+		return m, m.loadTasksCmd()
+
 	case "p":
 		// Enter post mode
 		if m.SelectedTopic != nil {
@@ -454,11 +694,137 @@ func (m Model) postMessageCmd(sender, content string) tea.Cmd {
 		if m.SelectedTopic == nil {
 			return MessagePostedMsg{Error: nil}
 		}
-		_, err := m.db.PostMessage(int64(m.SelectedTopic.ID), sender, content)
+		// The orchestrator's nudges skip its own posts, so nobody may post as it
+		if strings.EqualFold(sender, db.OrchestratorAgent) {
+			return MessagePostedMsg{Error: fmt.Errorf("%q is reserved for the orchestrator", db.OrchestratorAgent)}
+		}
+		topicID := int64(m.SelectedTopic.ID)
+		args := map[string]interface{}{"topic_id": topicID, "content": content}
+		masked, redactions, err := m.redact(sender, db.RedactionSourcePost, topicID, content)
+		if err != nil {
+			m.audit(sender, "bbs_post", args, err)
+			return MessagePostedMsg{Error: err}
+		}
+		id, err := m.db.PostMessage(topicID, sender, masked)
+		m.audit(sender, "bbs_post", args, err)
+		if err == nil {
+			_ = m.db.RecordRedactions(sender, db.RedactionSourcePost, topicID, id, redactions)
+		}
 		return MessagePostedMsg{Error: err}
 	}
 }
 
+func (m Model) searchCmd(query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := m.db.SearchMessages(query, db.SearchFilter{IncludeSummaries: true, Limit: 50})
+		return SearchResultsMsg{Query: query, Results: results, Error: err}
+	}
+}
+
+// operator returns the name the dashboard user posts and receives DMs as.
+func (m Model) operator() string {
+	if sender := m.SenderInput.Value(); sender != "" {
+		return sender
+	}
+	return "Human"
+}
+
+// dmCounterpart returns the other party of a direct message.
+func (m Model) dmCounterpart(dm db.DirectMessage) string {
+	if dm.Sender == m.operator() {
+		return dm.Recipient
+	}
+	return dm.Sender
+}
+
+// loadDMsCmd loads the operator's direct messages and marks received ones read.
+func (m Model) loadDMsCmd() tea.Cmd {
+	operator := m.operator()
+	return func() tea.Msg {
+		messages, err := m.db.GetDirectMessages(db.DMQuery{Agent: operator, Limit: 50})
+		if err != nil {
+			return DMsLoadedMsg{Error: err}
+		}
+		var received []int64
+		for _, dm := range messages {
+			if dm.Recipient == operator && !dm.Read {
+				received = append(received, int64(dm.ID))
+			}
+		}
+		if _, err := m.db.MarkDirectMessagesRead(operator, received); err != nil {
+			return DMsLoadedMsg{Error: err}
+		}
+		return DMsLoadedMsg{Messages: messages}
+	}
+}
+
+func (m Model) loadUnreadDMsCmd() tea.Cmd {
+	operator := m.operator()
+	return func() tea.Msg {
+		count, err := m.db.CountUnreadDirectMessages(operator)
+		if err != nil {
+			return nil
+		}
+		return UnreadDMsMsg(count)
+	}
+}
+
+func (m Model) sendDMCmd(to, content string) tea.Cmd {
+	operator := m.operator()
+	return func() tea.Msg {
+		args := map[string]interface{}{"to": to, "content": content}
+		masked, redactions, err := m.redact(operator, db.RedactionSourceDM, 0, content)
+		if err != nil {
+			m.audit(operator, "bbs_send_dm", args, err)
+			return DMSentMsg{Error: err}
+		}
+		id, err := m.db.SendDirectMessage(operator, to, masked)
+		m.audit(operator, "bbs_send_dm", args, err)
+		if err == nil {
+			_ = m.db.RecordRedactions(operator, db.RedactionSourceDM, 0, id, redactions)
+		}
+		return DMSentMsg{Error: err}
+	}
+}
+
+// redact runs content through the redaction rules, like the MCP tools do.
+// It returns the content to store and the redactions to record once it has
+// an ID, or an error if a rule rejects the content. Rejections are recorded
+// here; like audit failures, a failure to record them is ignored.
+func (m Model) redact(sender, source string, topicID int64, content string) (string, []db.Redaction, error) {
+	redactor, err := m.db.Redactor(m.Redaction)
+	if err != nil {
+		return "", nil, err
+	}
+
+	masked, redactions := redactor.Redact(content)
+	if rejected := db.Rejecting(redactions); len(rejected) > 0 {
+		_ = m.db.RecordRedactions(sender, source, topicID, 0, redactions)
+		return "", nil, fmt.Errorf("refused: the content looks like it contains a secret (%s)", strings.Join(rejected, ", "))
+	}
+	return masked, redactions, nil
+}
+
+// audit records a dashboard action in the audit log. A failure to record
+// doesn't fail the action; the dashboard has nowhere to log it.
+func (m Model) audit(actor, action string, args map[string]interface{}, err error) {
+	_ = m.db.RecordAudit(db.NewAuditEvent(actor, "", db.AuditSourceTUI, action, args, err))
+}
+
+// loadTasksCmd loads the tasks shown on the board, leaving out those in
+// topics the operator may not read.
+func (m Model) loadTasksCmd() tea.Cmd {
+	reader := db.Principal{Agent: m.operator()}
+	return func() tea.Msg {
+		tasks, err := m.db.ListTasks(db.TaskQuery{
+			Statuses: []string{db.TaskDraft, db.TaskTodo, db.TaskInProgress, db.TaskDone},
+			Reader:   &reader,
+			Limit:    500,
+		})
+		return TasksLoadedMsg{Tasks: tasks, Error: err}
+	}
+}
+
 func (m Model) tickCmd() tea.Cmd {
 	return tea.Tick(10*time.Second, func(t time.Time) tea.Msg {
 		return TickMsg(t)
@@ -471,3 +837,10 @@ func (m Model) loadPresenceCmd() tea.Cmd {
 		return PresenceLoadedMsg{Presences: presences, Error: err}
 	}
 }
+
+func (m Model) loadLocksCmd() tea.Cmd {
+	return func() tea.Msg {
+		locks, err := m.db.ListLocks("")
+		return LocksLoadedMsg{Locks: locks, Error: err}
+	}
+}
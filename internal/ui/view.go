@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/yklcs/agent-hub-mcp/internal/db"
 )
 
 var (
@@ -26,10 +29,15 @@ var (
 	summaryHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("228")).Bold(true)
 	summaryBadgeReal   = lipgloss.NewStyle().Foreground(lipgloss.Color("76")).Background(lipgloss.Color("235")).Padding(0, 1)
 	summaryBadgeMock   = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Background(lipgloss.Color("235")).Padding(0, 1)
+	summaryTitleStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("117")).Bold(true)
 	focusedBorderStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("226"))
 	onlineStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("76"))
 	offlineStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 	presenceHeader     = lipgloss.NewStyle().Foreground(lipgloss.Color("117")).Bold(true)
+	replyStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	mentionStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	verifiedBadge      = lipgloss.NewStyle().Foreground(lipgloss.Color("76"))
+	invalidBadge       = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
 )
 
 // Topic selector styles
@@ -45,6 +53,30 @@ var (
 	topicSelectorHint   = lipgloss.NewStyle().Faint(true).MarginTop(1)
 )
 
+// Search styles
+var (
+	searchStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("117")).
+			Padding(1, 2).
+			Width(80)
+	searchMatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("226"))
+)
+
+// Task board styles
+var (
+	taskColumnStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("238")).
+			Padding(0, 1)
+	taskTitleStyle   = lipgloss.NewStyle().Bold(true)
+	taskBlockedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	taskUrgentStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true)
+)
+
+// doneTasksShown is the number of recently completed tasks on the board.
+const doneTasksShown = 10
+
 // View renders the model with 2-column vertical layout.
 func (m Model) View() string {
 	if m.Loading {
@@ -60,6 +92,21 @@ func (m Model) View() string {
 		return m.renderTopicSelector()
 	}
 
+	// Search modal
+	if m.InputMode == ModeSearch {
+		return m.renderSearch()
+	}
+
+	// Direct message inbox
+	if m.InputMode == ModeDMs {
+		return m.renderDMInbox()
+	}
+
+	// Task board
+	if m.InputMode == ModeTasks {
+		return m.renderTaskBoard()
+	}
+
 	// Calculate dimensions
 	leftWidth := m.Width / 3
 	rightWidth := m.Width - leftWidth - 2 // Account for spacing
@@ -77,9 +124,14 @@ func (m Model) View() string {
 
 	halfHeight := contentHeight / 2
 
-	// Build left column: Topics (top) + Agents (bottom)
+	// Build left column: Topics (top) + Agents and Locks (bottom)
 	topicsContent := m.renderTopicsPane()
 	agentsContent := m.renderAgentsPane()
+	agentsHeight := halfHeight / 2
+	locksHeight := halfHeight - agentsHeight - 4 // Border and padding of the extra pane
+	if locksHeight < 3 {
+		locksHeight = 3
+	}
 
 	var topicsPane, agentsPane string
 	if m.FocusPane == PaneTopics {
@@ -89,12 +141,13 @@ func (m Model) View() string {
 	}
 
 	if m.FocusPane == PaneAgents {
-		agentsPane = focusedBorderStyle.Width(leftWidth).Height(halfHeight).Render(agentsContent)
+		agentsPane = focusedBorderStyle.Width(leftWidth).Height(agentsHeight).Render(agentsContent)
 	} else {
-		agentsPane = agentsListStyle.Width(leftWidth).Height(halfHeight).Render(agentsContent)
+		agentsPane = agentsListStyle.Width(leftWidth).Height(agentsHeight).Render(agentsContent)
 	}
+	locksPane := agentsListStyle.Width(leftWidth).Height(locksHeight).Render(m.renderLocksPane())
 
-	leftColumn := lipgloss.JoinVertical(lipgloss.Left, topicsPane, agentsPane)
+	leftColumn := lipgloss.JoinVertical(lipgloss.Left, topicsPane, agentsPane, locksPane)
 
 	// Build right column: Messages (top) + Summaries (bottom)
 	messagesContent := m.renderMessagesPane()
@@ -142,7 +195,11 @@ func (m Model) View() string {
 		)
 		bottom = lipgloss.JoinVertical(lipgloss.Left, inputBox, helpStyle.Render("Enter: send | Esc: cancel"))
 	} else {
-		help := "h/j/k/l: nav | ←/→: focus | t: topics | [ / ]: summaries | r: refresh | p: post | q: quit"
+		dms := "d: DMs"
+		if m.UnreadDMs > 0 {
+			dms = fmt.Sprintf("d: DMs (%d new)", m.UnreadDMs)
+		}
+		help := "h/j/k/l: nav | ←/→: focus | t: topics | /: search | " + dms + " | b: tasks | [ / ]: summaries | r: refresh | p: post | q: quit"
 		bottom = helpStyle.Render(help)
 	}
 
@@ -191,14 +248,56 @@ func (m Model) renderAgentsPane() string {
 	return agentsList.String()
 }
 
+// renderLocksPane renders the advisory locks held by agents.
+func (m Model) renderLocksPane() string {
+	var locksList strings.Builder
+	locksList.WriteString(presenceHeader.Render("Locks") + "\n\n")
+
+	if len(m.Locks) == 0 {
+		locksList.WriteString(dimStyle.Render("No locks held."))
+		return locksList.String()
+	}
+
+	for _, l := range m.Locks {
+		locksList.WriteString("🔒 " + l.Resource + "\n")
+		details := l.Holder + " · " + formatRemaining(l.ExpiresInSec) + " left"
+		if l.Note != "" {
+			details += " · " + l.Note
+		}
+		locksList.WriteString(dimStyle.Render("  "+details) + "\n")
+	}
+
+	return locksList.String()
+}
+
+// formatRemaining formats a number of seconds as a short duration.
+func formatRemaining(sec int) string {
+	switch {
+	case sec < 60:
+		return fmt.Sprintf("%ds", max(sec, 0))
+	case sec < 3600:
+		return fmt.Sprintf("%dm", sec/60)
+	default:
+		return fmt.Sprintf("%dh%02dm", sec/3600, sec%3600/60)
+	}
+}
+
 // renderMessagesPane renders the messages pane.
 func (m Model) renderMessagesPane() string {
 	var messageList strings.Builder
 	if m.SelectedTopic != nil {
 		messageList.WriteString(titleStyle.Render(m.SelectedTopic.Title) + "\n\n")
-		for _, msg := range m.Messages {
-			messageList.WriteString(senderStyle.Render(msg.Sender + ": "))
-			messageList.WriteString(msg.Content + "\n")
+		agentNames := make([]string, len(m.Presences))
+		for i, p := range m.Presences {
+			agentNames[i] = p.Name
+		}
+		for _, tm := range threadMessages(m.Messages) {
+			if tm.Depth > 0 {
+				messageList.WriteString(strings.Repeat("  ", tm.Depth-1) + replyStyle.Render("  ↳ "))
+			}
+			messageList.WriteString(senderStyle.Render(tm.Msg.Sender))
+			messageList.WriteString(signatureBadge(tm.Msg.Verification) + senderStyle.Render(": "))
+			messageList.WriteString(messageContent(tm.Msg, agentNames) + "\n")
 		}
 		if len(m.Messages) == 0 {
 			messageList.WriteString(dimStyle.Render("No messages yet. Press 'p' to post."))
@@ -209,6 +308,84 @@ func (m Model) renderMessagesPane() string {
 	return messageList.String()
 }
 
+// messageContent renders the content of a message: a faint tombstone for a
+// deleted message, and an "(edited)" marker after edited ones, both naming
+// who made the change.
+func messageContent(msg db.Message, agentNames []string) string {
+	by := ""
+	if msg.EditedBy != "" {
+		by = " by " + msg.EditedBy
+	}
+	if msg.Deleted {
+		return dimStyle.Render(db.DeletedContent + by)
+	}
+	content := highlightMentions(msg.Content, agentNames)
+	if msg.EditedAt != "" {
+		content += dimStyle.Render(" (edited" + by + ")")
+	}
+	return content
+}
+
+// signatureBadge marks signed messages after the sender's name: a check
+// mark if the signature is verified, a warning if it is not. Unsigned
+// messages get no badge.
+func signatureBadge(verification string) string {
+	switch verification {
+	case db.VerificationVerified:
+		return verifiedBadge.Render(" ✓")
+	case db.VerificationInvalid:
+		return invalidBadge.Render(" ✗ bad signature")
+	default:
+		return ""
+	}
+}
+
+// maxThreadIndent caps reply indentation so deep threads stay readable.
+const maxThreadIndent = 4
+
+// threadedMessage is a message positioned in the threaded messages pane.
+type threadedMessage struct {
+	Msg   db.Message
+	Depth int
+}
+
+// threadMessages orders messages so that replies follow their parent,
+// indented one level per reply. Top-level messages keep their original
+// order; replies are listed oldest first. A reply whose parent is not
+// loaded is shown at the top level.
+func threadMessages(messages []db.Message) []threadedMessage {
+	loaded := make(map[int]bool, len(messages))
+	for _, msg := range messages {
+		loaded[msg.ID] = true
+	}
+
+	replies := make(map[int][]db.Message)
+	var roots []db.Message
+	for _, msg := range messages {
+		if msg.ReplyTo != 0 && loaded[msg.ReplyTo] {
+			replies[msg.ReplyTo] = append(replies[msg.ReplyTo], msg)
+		} else {
+			roots = append(roots, msg)
+		}
+	}
+	for id := range replies {
+		sort.Slice(replies[id], func(i, j int) bool { return replies[id][i].ID < replies[id][j].ID })
+	}
+
+	ordered := make([]threadedMessage, 0, len(messages))
+	var walk func(msg db.Message, depth int)
+	walk = func(msg db.Message, depth int) {
+		ordered = append(ordered, threadedMessage{Msg: msg, Depth: min(depth, maxThreadIndent)})
+		for _, reply := range replies[msg.ID] {
+			walk(reply, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return ordered
+}
+
 // renderSummariesPane renders the summaries pane.
 func (m Model) renderSummariesPane() string {
 	var summaryList strings.Builder
@@ -232,14 +409,25 @@ func (m Model) renderSummariesPane() string {
 		} else {
 			summaryList.WriteString(summaryBadgeReal.Render(" Gemini ✅ "))
 		}
-		summaryList.WriteString("\n\n")
+		summaryList.WriteString("\n")
 
-		// Summary text (truncate if too long)
-		summaryText := s.SummaryText
-		if len(summaryText) > 500 {
-			summaryText = summaryText[:497] + "..."
+		// Covered messages
+		if s.LastMessageID > 0 && s.MessageCount > 0 {
+			summaryList.WriteString(dimStyle.Render(fmt.Sprintf("Covers #%d-#%d (%d messages)", s.FirstMessageID, s.LastMessageID, s.MessageCount)))
+			summaryList.WriteString("\n")
+		}
+		summaryList.WriteString("\n")
+
+		if s.Sections != nil {
+			summaryList.WriteString(renderSummarySections(s.Sections))
+		} else {
+			// Summary text (truncate if too long)
+			summaryText := s.SummaryText
+			if len(summaryText) > 500 {
+				summaryText = summaryText[:497] + "..."
+			}
+			summaryList.WriteString(summaryText)
 		}
-		summaryList.WriteString(summaryText)
 
 		// Navigation hint
 		summaryList.WriteString("\n\n")
@@ -249,6 +437,43 @@ func (m Model) renderSummariesPane() string {
 	return summaryList.String()
 }
 
+// renderSummarySections renders the overview and each non-empty section of
+// a structured summary under its own heading.
+func renderSummarySections(sections *db.SummarySections) string {
+	var sb strings.Builder
+
+	overview := sections.Overview
+	if len(overview) > 300 {
+		overview = overview[:297] + "..."
+	}
+	sb.WriteString(overview)
+
+	writeSection := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		sb.WriteString("\n\n" + summaryTitleStyle.Render(title))
+		for _, item := range items {
+			sb.WriteString("\n• " + item)
+		}
+	}
+
+	writeSection("Decisions", sections.Decisions)
+	var actions []string
+	for _, item := range sections.ActionItems {
+		if item.Owner != "" {
+			actions = append(actions, item.Task+" "+senderStyle.Render("@"+item.Owner))
+		} else {
+			actions = append(actions, item.Task+" "+dimStyle.Render("(unassigned)"))
+		}
+	}
+	writeSection("Action Items", actions)
+	writeSection("Open Questions", sections.OpenQuestions)
+	writeSection("Risks", sections.Risks)
+
+	return sb.String()
+}
+
 // renderTopicSelector renders the topic selector modal.
 func (m Model) renderTopicSelector() string {
 	var sb strings.Builder
@@ -277,3 +502,194 @@ func (m Model) renderTopicSelector() string {
 
 	return topicSelectorStyle.Render(sb.String())
 }
+
+// renderSearch renders the search modal with its results.
+func (m Model) renderSearch() string {
+	var sb strings.Builder
+
+	sb.WriteString(topicSelectorTitle.Render("Search") + "\n\n")
+	sb.WriteString(m.SearchInput.View() + "\n\n")
+
+	switch {
+	case m.SearchedQuery == "":
+		sb.WriteString(dimStyle.Render("Type a query and press Enter."))
+	case len(m.SearchResults) == 0:
+		sb.WriteString(dimStyle.Render("No matches found."))
+	default:
+		for i, r := range m.SearchResults {
+			header := fmt.Sprintf("#%d %s · %s", r.TopicID, r.TopicTitle, r.Sender)
+			if r.Kind == "summary" {
+				header += " (summary)"
+			}
+			if i == m.SearchIdx {
+				sb.WriteString(topicSelectorCursor.Render("▶ " + header))
+			} else {
+				sb.WriteString("  " + senderStyle.Render(header))
+			}
+			sb.WriteString("\n    " + highlightSnippet(r.Snippet) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(topicSelectorHint.Render("Enter: search / open topic | ↑/↓: select | Esc: cancel"))
+
+	return searchStyle.Render(sb.String())
+}
+
+// renderDMInbox renders the operator's direct messages with a reply box.
+func (m Model) renderDMInbox() string {
+	var sb strings.Builder
+
+	sb.WriteString(topicSelectorTitle.Render("Direct Messages · "+m.operator()) + "\n\n")
+
+	if len(m.DMs) == 0 {
+		sb.WriteString(dimStyle.Render("No direct messages yet."))
+	}
+	for i, dm := range m.DMs {
+		header := fmt.Sprintf("%s → %s", dm.Sender, dm.Recipient)
+		if dm.Recipient == m.operator() && !dm.Read {
+			header += " (new)"
+		}
+		if i == m.DMIdx {
+			sb.WriteString(topicSelectorCursor.Render("▶ " + header))
+		} else {
+			sb.WriteString("  " + senderStyle.Render(header))
+		}
+		sb.WriteString(dimStyle.Render("  "+dm.CreatedAt) + "\n    " + dm.Content + "\n")
+	}
+
+	sb.WriteString("\n")
+	if m.DMReplying && m.DMIdx < len(m.DMs) {
+		sb.WriteString(senderStyle.Render("To "+m.dmCounterpart(m.DMs[m.DMIdx])+": ") + m.DMInput.View() + "\n")
+		sb.WriteString(topicSelectorHint.Render("Enter: send | Esc: cancel"))
+	} else {
+		sb.WriteString(topicSelectorHint.Render("↑/k: up | ↓/j: down | Enter: reply | Esc: close"))
+	}
+
+	return searchStyle.Render(sb.String())
+}
+
+// renderTaskBoard renders the task board as kanban columns.
+func (m Model) renderTaskBoard() string {
+	columns := []struct {
+		title  string
+		status string
+	}{
+		{"Draft", db.TaskDraft},
+		{"To Do", db.TaskTodo},
+		{"In Progress", db.TaskInProgress},
+		{"Done", db.TaskDone},
+	}
+
+	width := (m.Width - 2) / len(columns)
+	if width < 24 {
+		width = 24
+	}
+
+	var rendered []string
+	for _, column := range columns {
+		var tasks []db.Task
+		for _, task := range m.Tasks {
+			if task.Status == column.status {
+				tasks = append(tasks, task)
+			}
+		}
+		hidden := 0
+		if column.status == db.TaskDone {
+			// Most recently completed first
+			sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CompletedAt > tasks[j].CompletedAt })
+			if len(tasks) > doneTasksShown {
+				hidden = len(tasks) - doneTasksShown
+				tasks = tasks[:doneTasksShown]
+			}
+		}
+
+		var sb strings.Builder
+		sb.WriteString(presenceHeader.Render(fmt.Sprintf("%s (%d)", column.title, len(tasks)+hidden)) + "\n")
+		if len(tasks) == 0 {
+			sb.WriteString(dimStyle.Render("\nNo tasks"))
+		}
+		for _, task := range tasks {
+			sb.WriteString("\n" + renderTaskCard(task) + "\n")
+		}
+		if hidden > 0 {
+			sb.WriteString(dimStyle.Render(fmt.Sprintf("\n+%d older", hidden)))
+		}
+		rendered = append(rendered, taskColumnStyle.Width(width-2).Render(sb.String()))
+	}
+
+	board := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	header := topicSelectorTitle.Render("Task Board")
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", board, helpStyle.Render("r: refresh | Esc/b: close"))
+}
+
+// renderTaskCard renders one task on the board.
+func renderTaskCard(task db.Task) string {
+	title := taskTitleStyle.Render(fmt.Sprintf("#%d %s", task.ID, task.Title))
+
+	var details []string
+	if task.Assignee != "" {
+		details = append(details, senderStyle.Render("@"+task.Assignee))
+	}
+	switch task.Priority {
+	case db.TaskUrgent, db.TaskHigh:
+		details = append(details, taskUrgentStyle.Render(task.Priority))
+	case db.TaskLow:
+		details = append(details, dimStyle.Render(task.Priority))
+	}
+	if task.TopicID != 0 {
+		details = append(details, dimStyle.Render(fmt.Sprintf("topic #%d", task.TopicID)))
+	}
+
+	card := title
+	if len(details) > 0 {
+		card += "\n" + strings.Join(details, " · ")
+	}
+	if task.Blocked {
+		blockers := make([]string, len(task.BlockedBy))
+		for i, id := range task.BlockedBy {
+			blockers[i] = fmt.Sprintf("#%d", id)
+		}
+		card += "\n" + taskBlockedStyle.Render("blocked by "+strings.Join(blockers, ", "))
+	}
+	return card
+}
+
+// highlightMentions styles @mentions of known agents in message content.
+func highlightMentions(content string, agentNames []string) string {
+	spans := db.FindMentions(content, agentNames)
+	if len(spans) == 0 {
+		return content
+	}
+	var sb strings.Builder
+	last := 0
+	for _, span := range spans {
+		sb.WriteString(content[last:span.Start])
+		sb.WriteString(mentionStyle.Render(content[span.Start:span.End]))
+		last = span.End
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// highlightSnippet styles the matched terms marked by the search snippet.
+func highlightSnippet(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+	var sb strings.Builder
+	for {
+		start := strings.Index(snippet, db.HighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], db.HighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		sb.WriteString(snippet[:start])
+		sb.WriteString(searchMatchStyle.Render(snippet[start+len(db.HighlightStart) : end]))
+		snippet = snippet[end+len(db.HighlightEnd):]
+	}
+	sb.WriteString(snippet)
+	return sb.String()
+}